@@ -0,0 +1,375 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AnnotationConversionData keeps the parts of a claim which cannot be represented in the API version it is converted
+// to, so that converting it back is lossless. It is removed again when converting back.
+const AnnotationConversionData string = GroupName + "/conversion-data"
+
+// legacyTokenLength is the fixed length of the tokens generated for v1beta1 tokenFields.
+const legacyTokenLength = 16
+
+type conversionData struct {
+	// FieldOrder is the order of the fields of a v1 claim if it differs from the order they would be converted in
+	FieldOrder []string `json:"fieldOrder,omitempty"`
+	// PrivateKeyFormat of a v1 claim if it is set explicitly to the default
+	PrivateKeyFormat PrivateKeyFormat `json:"privateKeyFormat,omitempty"`
+	// CustomTokenFields are v1beta1 custom tokens which would otherwise be converted back to tokenFields
+	CustomTokenFields []string `json:"customTokenFields,omitempty"`
+}
+
+// popConversionData removes the conversion data annotation from meta and returns its contents.
+func popConversionData(meta *metav1.ObjectMeta) (*conversionData, error) {
+	var data conversionData
+	raw, ok := meta.Annotations[AnnotationConversionData]
+	if !ok {
+		return &data, nil
+	}
+	delete(meta.Annotations, AnnotationConversionData)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", AnnotationConversionData, err)
+	}
+	return &data, nil
+}
+
+// pushConversionData stores data in the conversion data annotation of meta unless it is empty.
+func pushConversionData(meta *metav1.ObjectMeta, data *conversionData) error {
+	if len(data.FieldOrder) == 0 && data.PrivateKeyFormat == "" && len(data.CustomTokenFields) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[AnnotationConversionData] = string(raw)
+	return nil
+}
+
+// isLegacyToken checks if a token is equivalent to a v1beta1 tokenFields entry.
+func isLegacyToken(token *TokenSpec) bool {
+	return *token == TokenSpec{Length: legacyTokenLength, Encoding: TokenEncodingHex}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// convertFields converts the fields of a v1beta1 claim. They are ordered like in order, followed by the token fields
+// in their order and the fixed, custom token, OTP and referenced fields sorted by key.
+func convertFields(in *v1beta1.SecretClaimSpec, order []string, data *conversionData) []SecretField {
+	var fields []SecretField
+	for _, key := range in.TokenFields {
+		fields = append(fields, SecretField{Key: key, Token: &TokenSpec{Length: legacyTokenLength, Encoding: TokenEncodingHex}})
+	}
+	for _, key := range sortedKeys(in.FixedFields) {
+		value := in.FixedFields[key]
+		fields = append(fields, SecretField{Key: key, Value: &value})
+	}
+	for _, key := range sortedKeys(in.CustomTokenFields) {
+		spec := in.CustomTokenFields[key]
+		token := &TokenSpec{
+			Length:          spec.Length,
+			Encoding:        TokenEncoding(spec.Encoding),
+			CharacterSet:    spec.CharacterSet,
+			Prefix:          spec.Prefix,
+			Suffix:          spec.Suffix,
+			AllowLowEntropy: spec.AllowLowEntropy,
+			PasswordPolicy:  convertPasswordPolicyFromV1beta1(spec.PasswordPolicy),
+			Passphrase:      convertPassphraseFromV1beta1(spec.Passphrase),
+		}
+		if isLegacyToken(token) {
+			data.CustomTokenFields = append(data.CustomTokenFields, key)
+		}
+		fields = append(fields, SecretField{Key: key, Token: token})
+	}
+	for _, key := range sortedKeys(in.OTPFields) {
+		fields = append(fields, SecretField{Key: key, OTP: convertOTPFromV1beta1(in.OTPFields[key])})
+	}
+	for _, key := range sortedKeys(in.ValueFromFields) {
+		source := in.ValueFromFields[key]
+		fields = append(fields, SecretField{Key: key, ValueFrom: &ValueFromSource{
+			SecretKeyRef:    source.SecretKeyRef,
+			ConfigMapKeyRef: source.ConfigMapKeyRef,
+		}})
+	}
+	position := make(map[string]int)
+	for i, key := range order {
+		position[key] = i - len(order)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return position[fields[i].Key] < position[fields[j].Key]
+	})
+	return fields
+}
+
+func convertPasswordPolicyFromV1beta1(in *v1beta1.PasswordPolicy) *PasswordPolicy {
+	if in == nil {
+		return nil
+	}
+	out := &PasswordPolicy{
+		ExcludedCharacters:    in.ExcludedCharacters,
+		FirstCharacterClasses: in.FirstCharacterClasses,
+		LastCharacterClasses:  in.LastCharacterClasses,
+	}
+	for _, class := range in.Classes {
+		out.Classes = append(out.Classes, CharacterClass{Name: class.Name, Characters: class.Characters, MinCount: class.MinCount})
+	}
+	return out
+}
+
+func (in *PasswordPolicy) convertToV1beta1() *v1beta1.PasswordPolicy {
+	if in == nil {
+		return nil
+	}
+	out := &v1beta1.PasswordPolicy{
+		ExcludedCharacters:    in.ExcludedCharacters,
+		FirstCharacterClasses: in.FirstCharacterClasses,
+		LastCharacterClasses:  in.LastCharacterClasses,
+	}
+	for _, class := range in.Classes {
+		out.Classes = append(out.Classes, v1beta1.CharacterClass{Name: class.Name, Characters: class.Characters, MinCount: class.MinCount})
+	}
+	return out
+}
+
+func convertPassphraseFromV1beta1(in *v1beta1.PassphraseSpec) *PassphraseSpec {
+	if in == nil {
+		return nil
+	}
+	return &PassphraseSpec{Separator: in.Separator, Capitalization: Capitalization(in.Capitalization), WordListRef: in.WordListRef}
+}
+
+func (in *PassphraseSpec) convertToV1beta1() *v1beta1.PassphraseSpec {
+	if in == nil {
+		return nil
+	}
+	return &v1beta1.PassphraseSpec{Separator: in.Separator, Capitalization: v1beta1.Capitalization(in.Capitalization), WordListRef: in.WordListRef}
+}
+
+func convertOTPFromV1beta1(in v1beta1.OTPSpec) *OTPSpec {
+	return &OTPSpec{
+		Type:        OTPType(in.Type),
+		Issuer:      in.Issuer,
+		AccountName: in.AccountName,
+		Algorithm:   OTPAlgorithm(in.Algorithm),
+		Digits:      in.Digits,
+		Period:      in.Period,
+		Counter:     in.Counter,
+		SeedLength:  in.SeedLength,
+		QRCode:      in.QRCode,
+	}
+}
+
+func (in *OTPSpec) convertToV1beta1() v1beta1.OTPSpec {
+	return v1beta1.OTPSpec{
+		Type:        v1beta1.OTPType(in.Type),
+		Issuer:      in.Issuer,
+		AccountName: in.AccountName,
+		Algorithm:   v1beta1.OTPAlgorithm(in.Algorithm),
+		Digits:      in.Digits,
+		Period:      in.Period,
+		Counter:     in.Counter,
+		SeedLength:  in.SeedLength,
+		QRCode:      in.QRCode,
+	}
+}
+
+func fieldKeys(fields []SecretField) []string {
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	return keys
+}
+
+// ConvertFromV1beta1 converts a v1beta1 claim to v1.
+func ConvertFromV1beta1(in *v1beta1.SecretClaim) (*SecretClaim, error) {
+	in = in.DeepCopy()
+	out := &SecretClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: Kind},
+		ObjectMeta: in.ObjectMeta,
+	}
+	data, err := popConversionData(&out.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	outData := &conversionData{}
+	out.Spec = SecretClaimSpec{
+		Fields:               convertFields(&in.Spec, data.FieldOrder, outData),
+		AdoptionPolicy:       AdoptionPolicy(in.Spec.AdoptionPolicy),
+		DeletionPolicy:       DeletionPolicy(in.Spec.DeletionPolicy),
+		Immutable:            in.Spec.Immutable,
+		RevisionHistoryLimit: in.Spec.RevisionHistoryLimit,
+	}
+	if x := in.Spec.X509Claim; x != nil {
+		out.Spec.X509 = &X509Claim{
+			CASecretName:     x.CASecretName,
+			IsCA:             x.IsCA,
+			CommonName:       x.CommonName,
+			RotateEvery:      x.RotateEvery,
+			ServiceNames:     x.ServiceNames,
+			ExtraNames:       x.ExtraNames,
+			PrivateKeyFormat: data.PrivateKeyFormat,
+		}
+		if x.LegacySEC1PrivateKey {
+			out.Spec.X509.PrivateKeyFormat = PrivateKeyFormatSEC1
+		}
+	}
+	if t := in.Spec.SecretTemplate; t != nil {
+		out.Spec.SecretTemplate = &SecretTemplate{Name: t.Name, Type: t.Type, Labels: t.Labels, Annotations: t.Annotations}
+	}
+	if r := in.Spec.Replication; r != nil {
+		out.Spec.Replication = &Replication{Namespaces: r.Namespaces, NamespaceSelector: r.NamespaceSelector}
+	}
+	// The deprecated reason is never set and therefore dropped
+	out.Status = SecretClaimStatus{
+		ObservedGeneration:   in.Status.ObservedGeneration,
+		Conditions:           in.Status.Conditions,
+		LastRotationTime:     in.Status.LastRotationTime,
+		SecretName:           in.Status.SecretName,
+		ReplicatedNamespaces: in.Status.ReplicatedNamespaces,
+		TokenEntropyBits:     in.Status.TokenEntropyBits,
+	}
+	if c := in.Status.Certificate; c != nil {
+		out.Status.Certificate = &CertificateStatus{NotAfter: c.NotAfter, SerialNumber: c.SerialNumber, Fingerprint: c.Fingerprint}
+	}
+	if err := pushConversionData(&out.ObjectMeta, outData); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConvertToV1beta1 converts a v1 claim to v1beta1.
+func (sc *SecretClaim) ConvertToV1beta1() (*v1beta1.SecretClaim, error) {
+	in := sc.DeepCopy()
+	out := &v1beta1.SecretClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.Kind},
+		ObjectMeta: in.ObjectMeta,
+	}
+	data, err := popConversionData(&out.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	outData := &conversionData{}
+	out.Spec = v1beta1.SecretClaimSpec{
+		AdoptionPolicy:       v1beta1.AdoptionPolicy(in.Spec.AdoptionPolicy),
+		DeletionPolicy:       v1beta1.DeletionPolicy(in.Spec.DeletionPolicy),
+		Immutable:            in.Spec.Immutable,
+		RevisionHistoryLimit: in.Spec.RevisionHistoryLimit,
+	}
+	for _, f := range in.Spec.Fields {
+		switch {
+		case f.Value != nil:
+			if out.Spec.FixedFields == nil {
+				out.Spec.FixedFields = make(map[string]string)
+			}
+			out.Spec.FixedFields[f.Key] = *f.Value
+		case f.ValueFrom != nil:
+			if out.Spec.ValueFromFields == nil {
+				out.Spec.ValueFromFields = make(map[string]v1beta1.ValueFromSource)
+			}
+			out.Spec.ValueFromFields[f.Key] = v1beta1.ValueFromSource{
+				SecretKeyRef:    f.ValueFrom.SecretKeyRef,
+				ConfigMapKeyRef: f.ValueFrom.ConfigMapKeyRef,
+			}
+		case f.OTP != nil:
+			if out.Spec.OTPFields == nil {
+				out.Spec.OTPFields = make(map[string]v1beta1.OTPSpec)
+			}
+			out.Spec.OTPFields[f.Key] = f.OTP.convertToV1beta1()
+		case f.Token != nil && isLegacyToken(f.Token) && !contains(data.CustomTokenFields, f.Key):
+			out.Spec.TokenFields = append(out.Spec.TokenFields, f.Key)
+		case f.Token != nil:
+			if out.Spec.CustomTokenFields == nil {
+				out.Spec.CustomTokenFields = make(map[string]v1beta1.CustomTokenSpec)
+			}
+			out.Spec.CustomTokenFields[f.Key] = v1beta1.CustomTokenSpec{
+				Length:          f.Token.Length,
+				Encoding:        string(f.Token.Encoding),
+				CharacterSet:    f.Token.CharacterSet,
+				Prefix:          f.Token.Prefix,
+				Suffix:          f.Token.Suffix,
+				AllowLowEntropy: f.Token.AllowLowEntropy,
+				PasswordPolicy:  f.Token.PasswordPolicy.convertToV1beta1(),
+				Passphrase:      f.Token.Passphrase.convertToV1beta1(),
+			}
+		}
+	}
+	if order := fieldKeys(in.Spec.Fields); !equalStrings(order, fieldKeys(convertFields(&out.Spec, nil, &conversionData{}))) {
+		outData.FieldOrder = order
+	}
+	if x := in.Spec.X509; x != nil {
+		out.Spec.X509Claim = &v1beta1.X509Claim{
+			CASecretName:         x.CASecretName,
+			IsCA:                 x.IsCA,
+			CommonName:           x.CommonName,
+			RotateEvery:          x.RotateEvery,
+			ServiceNames:         x.ServiceNames,
+			ExtraNames:           x.ExtraNames,
+			LegacySEC1PrivateKey: x.PrivateKeyFormat == PrivateKeyFormatSEC1,
+		}
+		if x.PrivateKeyFormat != "" && x.PrivateKeyFormat != PrivateKeyFormatSEC1 {
+			outData.PrivateKeyFormat = x.PrivateKeyFormat
+		}
+	}
+	if t := in.Spec.SecretTemplate; t != nil {
+		out.Spec.SecretTemplate = &v1beta1.SecretTemplate{Name: t.Name, Type: t.Type, Labels: t.Labels, Annotations: t.Annotations}
+	}
+	if r := in.Spec.Replication; r != nil {
+		out.Spec.Replication = &v1beta1.Replication{Namespaces: r.Namespaces, NamespaceSelector: r.NamespaceSelector}
+	}
+	out.Status = v1beta1.SecretClaimStatus{
+		ObservedGeneration:   in.Status.ObservedGeneration,
+		Conditions:           in.Status.Conditions,
+		LastRotationTime:     in.Status.LastRotationTime,
+		SecretName:           in.Status.SecretName,
+		ReplicatedNamespaces: in.Status.ReplicatedNamespaces,
+		TokenEntropyBits:     in.Status.TokenEntropyBits,
+	}
+	if c := in.Status.Certificate; c != nil {
+		out.Status.Certificate = &v1beta1.CertificateStatus{NotAfter: c.NotAfter, SerialNumber: c.SerialNumber, Fingerprint: c.Fingerprint}
+	}
+	if err := pushConversionData(&out.ObjectMeta, outData); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,215 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	v1beta1TypeMeta = metav1.TypeMeta{APIVersion: "dolansoft.org/v1beta1", Kind: "SecretClaim"}
+	v1TypeMeta      = metav1.TypeMeta{APIVersion: "dolansoft.org/v1", Kind: "SecretClaim"}
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestConvertFromV1beta1(t *testing.T) {
+	in := &v1beta1.SecretClaim{
+		TypeMeta:   v1beta1TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token", "another"},
+			FixedFields: map[string]string{"user": "admin"},
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{
+				"password": {Length: 12, Encoding: "characterset", CharacterSet: "abc"},
+			},
+		},
+		Status: v1beta1.SecretClaimStatus{Reason: "deprecated", SecretName: "test"},
+	}
+	want := &SecretClaim{
+		TypeMeta:   v1TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: SecretClaimSpec{
+			Fields: []SecretField{
+				{Key: "token", Token: &TokenSpec{Length: 16, Encoding: TokenEncodingHex}},
+				{Key: "another", Token: &TokenSpec{Length: 16, Encoding: TokenEncodingHex}},
+				{Key: "user", Value: stringPtr("admin")},
+				{Key: "password", Token: &TokenSpec{Length: 12, Encoding: TokenEncodingCharacterSet, CharacterSet: "abc"}},
+			},
+		},
+		Status: SecretClaimStatus{SecretName: "test"},
+	}
+	got, err := ConvertFromV1beta1(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("ConvertFromV1beta1() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRoundTripV1beta1(t *testing.T) {
+	limit := int32(3)
+	notAfter := metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name string
+		sc   v1beta1.SecretClaim
+	}{
+		{"Empty", v1beta1.SecretClaim{}},
+		{"Token fields keep their order", v1beta1.SecretClaim{Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"z", "a", "m"},
+		}}},
+		{"All field kinds", v1beta1.SecretClaim{Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token"},
+			FixedFields: map[string]string{"user": "admin", "empty": ""},
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{
+				"password": {Length: 24, Encoding: "base64url", Prefix: "pw_", Suffix: "!"},
+				"id":       {Encoding: "uuid"},
+				"pin":      {Length: 4, Encoding: "characterset", CharacterSet: "0123456789", AllowLowEntropy: true},
+				"wifi": {Length: 5, Encoding: "passphrase", Passphrase: &v1beta1.PassphraseSpec{
+					Separator:      stringPtr(" "),
+					Capitalization: v1beta1.CapitalizationTitle,
+					WordListRef:    &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "words"}, Key: "list"},
+				}},
+				"legacy": {Length: 12, Encoding: "passwordpolicy", PasswordPolicy: &v1beta1.PasswordPolicy{
+					Classes: []v1beta1.CharacterClass{
+						{Name: "lowercase", MinCount: 1},
+						{Name: "digits", MinCount: 1},
+						{Name: "special", Characters: "!#%", MinCount: 1},
+					},
+					ExcludedCharacters:    "l0",
+					FirstCharacterClasses: []string{"lowercase"},
+				}},
+			},
+			ValueFromFields: map[string]v1beta1.ValueFromSource{
+				"host": {ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "config"}, Key: "host",
+				}},
+				"upstream": {SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "upstream"}, Key: "token",
+				}},
+			},
+		}}},
+		{"OTP fields", v1beta1.SecretClaim{Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token"},
+			OTPFields: map[string]v1beta1.OTPSpec{
+				"totp": {Issuer: "Example", AccountName: "bot@example.com", Algorithm: v1beta1.OTPAlgorithmSHA256, Digits: 8, Period: 60, QRCode: true},
+				"hotp": {Type: v1beta1.OTPTypeHOTP, AccountName: "bot", Counter: 5, SeedLength: 32},
+			},
+		}}},
+		{"Custom token equal to a token field", v1beta1.SecretClaim{Spec: v1beta1.SecretClaimSpec{
+			TokenFields:       []string{"token"},
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"custom": {Length: 16, Encoding: "hex"}},
+		}}},
+		{"X.509 and metadata", v1beta1.SecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "cert", Namespace: "default", Annotations: map[string]string{"a": "b"}},
+			Spec: v1beta1.SecretClaimSpec{
+				X509Claim: &v1beta1.X509Claim{
+					CASecretName:         "ca",
+					CommonName:           "My Service",
+					RotateEvery:          "90d",
+					ServiceNames:         []string{"hello"},
+					ExtraNames:           []string{"example.com"},
+					LegacySEC1PrivateKey: true,
+				},
+				AdoptionPolicy:       v1beta1.AdoptionPolicyAdopt,
+				DeletionPolicy:       v1beta1.DeletionPolicyRetain,
+				SecretTemplate:       &v1beta1.SecretTemplate{Name: "tls", Type: corev1.SecretTypeTLS, Labels: map[string]string{"app": "hello"}},
+				Immutable:            true,
+				RevisionHistoryLimit: &limit,
+				Replication: &v1beta1.Replication{
+					Namespaces:        []string{"other"},
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				},
+			},
+			Status: v1beta1.SecretClaimStatus{
+				ObservedGeneration:   2,
+				Conditions:           []metav1.Condition{{Type: v1beta1.ConditionReady, Status: metav1.ConditionTrue, Reason: "Reconciled"}},
+				Certificate:          &v1beta1.CertificateStatus{NotAfter: notAfter, SerialNumber: "1", Fingerprint: "ab"},
+				LastRotationTime:     &notAfter,
+				SecretName:           "tls",
+				ReplicatedNamespaces: []string{"other"},
+				TokenEntropyBits:     map[string]int32{"password": 192},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.sc.DeepCopy()
+			in.TypeMeta = v1beta1TypeMeta
+			converted, err := ConvertFromV1beta1(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := converted.ConvertToV1beta1()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equality.Semantic.DeepEqual(got, in) {
+				t.Errorf("round trip = %+v, want %+v", got, in)
+			}
+		})
+	}
+}
+
+func TestRoundTripV1(t *testing.T) {
+	tests := []struct {
+		name string
+		sc   SecretClaim
+	}{
+		{"Empty", SecretClaim{}},
+		{"Field order", SecretClaim{Spec: SecretClaimSpec{Fields: []SecretField{
+			{Key: "user", Value: stringPtr("admin")},
+			{Key: "password", Token: &TokenSpec{Length: 16, Encoding: TokenEncodingBase64}},
+			{Key: "token", Token: &TokenSpec{Length: 16, Encoding: TokenEncodingHex}},
+			{Key: "host", ValueFrom: &ValueFromSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "host"}}},
+		}}}},
+		{"Canonical field order", SecretClaim{Spec: SecretClaimSpec{Fields: []SecretField{
+			{Key: "token", Token: &TokenSpec{Length: 16, Encoding: TokenEncodingHex}},
+			{Key: "user", Value: stringPtr("admin")},
+		}}}},
+		{"Explicit default private key format", SecretClaim{Spec: SecretClaimSpec{
+			X509: &X509Claim{PrivateKeyFormat: PrivateKeyFormatPKCS8},
+		}}},
+		{"SEC1 private key", SecretClaim{Spec: SecretClaimSpec{
+			X509: &X509Claim{IsCA: true, PrivateKeyFormat: PrivateKeyFormatSEC1},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.sc.DeepCopy()
+			in.TypeMeta = v1TypeMeta
+			converted, err := in.ConvertToV1beta1()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ConvertFromV1beta1(converted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equality.Semantic.DeepEqual(got, in) {
+				t.Errorf("round trip = %+v, want %+v", got, in)
+			}
+		})
+	}
+}
+
+func TestConversionDataOnlyWhenNeeded(t *testing.T) {
+	sc := &SecretClaim{Spec: SecretClaimSpec{Fields: []SecretField{
+		{Key: "token", Token: &TokenSpec{Length: 16, Encoding: TokenEncodingHex}},
+		{Key: "user", Value: stringPtr("admin")},
+	}}}
+	converted, err := sc.ConvertToV1beta1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := converted.Annotations[AnnotationConversionData]; ok {
+		t.Errorf("unexpected conversion data %q", converted.Annotations[AnnotationConversionData])
+	}
+}
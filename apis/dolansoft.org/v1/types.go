@@ -0,0 +1,373 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	GroupName string = "dolansoft.org"
+	Kind      string = "SecretClaim"
+	Version   string = "v1"
+	Plural    string = "secretclaims"
+	Singular  string = "secretclaim"
+	ShortName string = "secc"
+	Name      string = Plural + "." + GroupName
+)
+
+// TokenEncoding determines how the random bytes of a token are encoded.
+// +kubebuilder:validation:Enum=base64;base64url;hex;upperhex;characterset;raw;uuid;passwordpolicy;passphrase;base32;crockford;base58;z85;ulid;uuidv7
+type TokenEncoding string
+
+const (
+	TokenEncodingBase64    TokenEncoding = "base64"
+	TokenEncodingBase64URL TokenEncoding = "base64url"
+	TokenEncodingHex       TokenEncoding = "hex"
+	TokenEncodingUpperHex  TokenEncoding = "upperhex"
+	// TokenEncodingCharacterSet picks each character of the token uniformly from CharacterSet
+	TokenEncodingCharacterSet TokenEncoding = "characterset"
+	TokenEncodingRaw          TokenEncoding = "raw"
+	// TokenEncodingUUID generates a random (version 4) UUID, the length is ignored
+	TokenEncodingUUID TokenEncoding = "uuid"
+	// TokenEncodingPasswordPolicy generates a token satisfying PasswordPolicy
+	TokenEncodingPasswordPolicy TokenEncoding = "passwordpolicy"
+	// TokenEncodingPassphrase generates a passphrase of Length words, see Passphrase
+	TokenEncodingPassphrase TokenEncoding = "passphrase"
+	// TokenEncodingBase32 is the padded base32 encoding of RFC 4648
+	TokenEncodingBase32 TokenEncoding = "base32"
+	// TokenEncodingCrockford is Crockford's base32 encoding without padding
+	TokenEncodingCrockford TokenEncoding = "crockford"
+	// TokenEncodingBase58 uses the Bitcoin alphabet, the length is limited to 256 bytes
+	TokenEncodingBase58 TokenEncoding = "base58"
+	// TokenEncodingZ85 is the ZeroMQ base85 encoding, the length needs to be a multiple of 4
+	TokenEncodingZ85 TokenEncoding = "z85"
+	// TokenEncodingULID generates a ULID with the current time, the length is ignored
+	TokenEncodingULID TokenEncoding = "ulid"
+	// TokenEncodingUUIDv7 generates a time-ordered version 7 UUID, the length is ignored
+	TokenEncodingUUIDv7 TokenEncoding = "uuidv7"
+)
+
+// TokenSpec describes a randomly generated token.
+// +kubebuilder:validation:XValidation:rule="self.encoding in ['uuid', 'uuidv7', 'ulid'] || (has(self.length) && self.length >= 1)",message="length needs to be at least 1"
+// +kubebuilder:validation:XValidation:rule="self.encoding != 'z85' || (has(self.length) && self.length % 4 == 0)",message="length needs to be a multiple of 4 for the z85 encoding"
+// +kubebuilder:validation:XValidation:rule="self.encoding != 'characterset' || (has(self.characterSet) && size(self.characterSet) > 0)",message="characterSet is required for the characterset encoding"
+// +kubebuilder:validation:XValidation:rule="(self.encoding == 'passwordpolicy') == has(self.passwordPolicy)",message="passwordPolicy is required for and only allowed with the passwordpolicy encoding"
+// +kubebuilder:validation:XValidation:rule="self.encoding == 'passphrase' || !has(self.passphrase)",message="passphrase is only allowed with the passphrase encoding"
+type TokenSpec struct {
+	// Length is the number of random bytes or, for the characterset and passwordpolicy encodings, characters of the
+	// token (at most 256 for passwordpolicy). For the passphrase encoding it is the number of words. It is ignored for
+	// the uuid, uuidv7 and ulid encodings.
+	// +kubebuilder:validation:Maximum=1048576
+	Length   int32         `json:"length,omitempty"`
+	Encoding TokenEncoding `json:"encoding"`
+	// All characters (unicode code points) in this string can be used to make up the token. Only used in the
+	// characterset encoding.
+	CharacterSet string `json:"characterSet,omitempty"`
+	// Prefix to put before the generated token.
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix to put after the generated token.
+	Suffix string `json:"suffix,omitempty"`
+	// AllowLowEntropy exempts the token from the minimum entropy configured on the controller, for example for
+	// PINs required by a legacy system. It does not exempt it from SecretClaimPolicies.
+	AllowLowEntropy bool `json:"allowLowEntropy,omitempty"`
+	// PasswordPolicy describes the characters the token is made of for the passwordpolicy encoding.
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy,omitempty"`
+	// Passphrase configures the passphrase encoding.
+	Passphrase *PassphraseSpec `json:"passphrase,omitempty"`
+}
+
+// Capitalization of the words of a passphrase.
+// +kubebuilder:validation:Enum=lower;title;upper;random
+type Capitalization string
+
+const (
+	// CapitalizationLower writes all words in lower case. This is the default.
+	CapitalizationLower Capitalization = "lower"
+	// CapitalizationTitle capitalizes the first letter of every word.
+	CapitalizationTitle Capitalization = "title"
+	// CapitalizationUpper writes all words in upper case.
+	CapitalizationUpper Capitalization = "upper"
+	// CapitalizationRandom capitalizes the first letter of each word with a probability of one half. This adds
+	// entropy only for words which change in title case, one bit per word if all of them do.
+	CapitalizationRandom Capitalization = "random"
+)
+
+// PassphraseSpec describes passphrases made up of words drawn from a word list.
+type PassphraseSpec struct {
+	// Separator between the words. Defaults to "-".
+	Separator *string `json:"separator,omitempty"`
+	// Capitalization of the words. Defaults to lower.
+	Capitalization Capitalization `json:"capitalization,omitempty"`
+	// WordListRef selects a key of a ConfigMap in the namespace of the claim containing the word list, one word per
+	// line. Lines of diceware lists like "11111 abacus" are supported, empty lines and lines starting with # are
+	// ignored. Defaults to the EFF large word list. The entropy of the passphrases is computed from the number of
+	// distinct words in the list.
+	WordListRef *corev1.ConfigMapKeySelector `json:"wordListRef,omitempty"`
+}
+
+// CharacterClass is a named set of characters of a PasswordPolicy.
+type CharacterClass struct {
+	// Name of the class. If Characters is empty, it must be one of the predefined classes lowercase, uppercase,
+	// digits or symbols.
+	Name string `json:"name"`
+	// Characters (unicode code points) of the class. Classes must not share characters.
+	Characters string `json:"characters,omitempty"`
+	// MinCount is the number of characters of the token which at least need to be from this class.
+	// +kubebuilder:validation:Minimum=0
+	MinCount int32 `json:"minCount,omitempty"`
+}
+
+// PasswordPolicy describes tokens for systems with password complexity rules. Every character of the token is
+// picked from the union of all classes and tokens are drawn uniformly from all tokens satisfying the policy.
+type PasswordPolicy struct {
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	Classes []CharacterClass `json:"classes"`
+	// ExcludedCharacters are never used, for example ambiguous characters like 0O1lI.
+	ExcludedCharacters string `json:"excludedCharacters,omitempty"`
+	// FirstCharacterClasses restricts the first character to the named classes, for example to not start with a
+	// digit. All classes are allowed if empty.
+	FirstCharacterClasses []string `json:"firstCharacterClasses,omitempty"`
+	// LastCharacterClasses restricts the last character to the named classes. All classes are allowed if empty.
+	LastCharacterClasses []string `json:"lastCharacterClasses,omitempty"`
+}
+
+// OTPType is the kind of one-time passwords generated from an OTP seed.
+// +kubebuilder:validation:Enum=totp;hotp
+type OTPType string
+
+const (
+	// OTPTypeTOTP generates time-based one-time passwords (RFC 6238). This is the default.
+	OTPTypeTOTP OTPType = "totp"
+	// OTPTypeHOTP generates counter-based one-time passwords (RFC 4226).
+	OTPTypeHOTP OTPType = "hotp"
+)
+
+// OTPAlgorithm is the HMAC hash function of one-time passwords.
+// +kubebuilder:validation:Enum=SHA1;SHA256;SHA512
+type OTPAlgorithm string
+
+const (
+	// OTPAlgorithmSHA1 is the default and the only algorithm supported by all authenticator apps.
+	OTPAlgorithmSHA1   OTPAlgorithm = "SHA1"
+	OTPAlgorithmSHA256 OTPAlgorithm = "SHA256"
+	OTPAlgorithmSHA512 OTPAlgorithm = "SHA512"
+)
+
+// OTPSpec describes a seed for one-time passwords. The seed is stored base32-encoded without padding in the key of
+// the field and the otpauth:// provisioning URI in <key>.uri. If QRCode is set, a PNG image of a QR code of the URI is
+// stored in <key>.png. The URI and QR code are derived from the seed and updated if the other settings change.
+// +kubebuilder:validation:XValidation:rule="!has(self.period) || !has(self.type) || self.type == 'totp'",message="period is only allowed for totp"
+// +kubebuilder:validation:XValidation:rule="!has(self.counter) || (has(self.type) && self.type == 'hotp')",message="counter is only allowed for hotp"
+type OTPSpec struct {
+	// Type of the one-time passwords, totp (the default) or hotp.
+	Type OTPType `json:"type,omitempty"`
+	// Issuer is the provider or service the account belongs to. It is shown by authenticator apps and must not
+	// contain a colon.
+	// +kubebuilder:validation:MaxLength=256
+	Issuer string `json:"issuer,omitempty"`
+	// AccountName identifies the account, for example an email address. It must not contain a colon.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	AccountName string `json:"accountName"`
+	// Algorithm is the HMAC hash function. Defaults to SHA1, which some authenticator apps assume regardless of
+	// this setting.
+	Algorithm OTPAlgorithm `json:"algorithm,omitempty"`
+	// Digits of each one-time password, 6 (the default) or 8.
+	// +kubebuilder:validation:Enum=6;8
+	Digits int32 `json:"digits,omitempty"`
+	// Period in seconds for which a time-based one-time password is valid. Defaults to 30.
+	// +kubebuilder:validation:Minimum=1
+	Period int32 `json:"period,omitempty"`
+	// Counter is the initial counter of counter-based one-time passwords.
+	// +kubebuilder:validation:Minimum=0
+	Counter int64 `json:"counter,omitempty"`
+	// SeedLength is the number of random bytes of the seed. Defaults to 20, RFC 4226 requires at least 16.
+	// +kubebuilder:validation:Minimum=16
+	// +kubebuilder:validation:Maximum=64
+	SeedLength int32 `json:"seedLength,omitempty"`
+	// QRCode additionally stores a PNG image of a QR code of the provisioning URI.
+	QRCode bool `json:"qrCode,omitempty"`
+}
+
+// ValueFromSource selects a value from another Secret or ConfigMap in the namespace of the claim. Exactly one of
+// SecretKeyRef and ConfigMapKeyRef must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.secretKeyRef) != has(self.configMapKeyRef)",message="exactly one of secretKeyRef and configMapKeyRef must be set"
+type ValueFromSource struct {
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// SecretField is a single key of the secret. Exactly one of Value, Token, ValueFrom and OTP must be set.
+// +kubebuilder:validation:XValidation:rule="(has(self.value) ? 1 : 0) + (has(self.token) ? 1 : 0) + (has(self.valueFrom) ? 1 : 0) + (has(self.otp) ? 1 : 0) == 1",message="exactly one of value, token, valueFrom and otp must be set"
+type SecretField struct {
+	Key string `json:"key"`
+	// Value is copied into the secret as is
+	Value *string `json:"value,omitempty"`
+	// Token is generated once and kept as long as the existing value satisfies the spec
+	Token *TokenSpec `json:"token,omitempty"`
+	// ValueFrom copies the value from another Secret or ConfigMap
+	ValueFrom *ValueFromSource `json:"valueFrom,omitempty"`
+	// OTP generates a seed for one-time passwords, see OTPSpec
+	OTP *OTPSpec `json:"otp,omitempty"`
+}
+
+// PrivateKeyFormat determines how the private key of a certificate is encoded.
+// +kubebuilder:validation:Enum=PKCS8;SEC1
+type PrivateKeyFormat string
+
+const (
+	// PrivateKeyFormatPKCS8 encodes the key as a "PRIVATE KEY" PEM block. This is the default.
+	PrivateKeyFormatPKCS8 PrivateKeyFormat = "PKCS8"
+	// PrivateKeyFormatSEC1 encodes the key as an "EC PRIVATE KEY" PEM block for legacy applications.
+	PrivateKeyFormatSEC1 PrivateKeyFormat = "SEC1"
+)
+
+// X509Claim is a claim for an X.509 certificate
+type X509Claim struct {
+	// CASecretName is the secret of the CA claim which signs the certificate, self-signed if empty
+	CASecretName string `json:"caSecretName,omitempty"`
+	IsCA         bool   `json:"isCA,omitempty"`
+	// CommonName defaults to the name of the claim
+	// +kubebuilder:validation:MaxLength=64
+	CommonName string `json:"commonName,omitempty"`
+	// RotateEvery is the validity period of the certificate, for example 90d
+	RotateEvery string `json:"rotateEvery,omitempty"`
+	// ServiceNames are added with all their in-cluster DNS names
+	ServiceNames     []string         `json:"serviceNames,omitempty"`
+	ExtraNames       []string         `json:"extraNames,omitempty"`
+	PrivateKeyFormat PrivateKeyFormat `json:"privateKeyFormat,omitempty"`
+}
+
+// AdoptionPolicy determines what happens if a secret with the name of the claim already exists, but is not controlled
+// by the claim.
+// +kubebuilder:validation:Enum=Refuse;Adopt
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyRefuse leaves the secret alone and reports a conflict. This is the default.
+	AdoptionPolicyRefuse AdoptionPolicy = "Refuse"
+	// AdoptionPolicyAdopt makes the claim the controller of the secret, keeping existing values which satisfy the
+	// claim and filling in the rest. Secrets controlled by something else are never adopted.
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+)
+
+// DeletionPolicy determines what happens to the secret of a claim when the claim is deleted. Foreground cascading
+// deletion of claims with Retain is rejected by the admission webhook, it would delete the secret before it is released.
+// +kubebuilder:validation:Enum=Delete;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the secret together with the claim. This is the default.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the secret when the claim is deleted.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// Replication configures other namespaces into which the secret of a claim is copied and kept in sync.
+type Replication struct {
+	// Namespaces into which the secret is replicated
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects additional namespaces into which the secret is replicated
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// SecretTemplate describes the metadata of the secret generated for a claim.
+type SecretTemplate struct {
+	// Name of the secret, defaults to the name of the claim
+	Name string `json:"name,omitempty"`
+	// Type of the secret, defaults to Opaque
+	Type        corev1.SecretType `json:"type,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type SecretClaimSpec struct {
+	// Fields of the secret, keys must be unique. Ignored for X.509 claims.
+	// +listType=map
+	// +listMapKey=key
+	Fields         []SecretField   `json:"fields,omitempty"`
+	X509           *X509Claim      `json:"x509,omitempty"`
+	AdoptionPolicy AdoptionPolicy  `json:"adoptionPolicy,omitempty"`
+	DeletionPolicy DeletionPolicy  `json:"deletionPolicy,omitempty"`
+	SecretTemplate *SecretTemplate `json:"secretTemplate,omitempty"`
+	// Immutable writes each generation of the data to a new immutable secret named after the secret and a hash of
+	// its contents. The name of the current secret is published in the status.
+	Immutable bool `json:"immutable,omitempty"`
+	// RevisionHistoryLimit is the number of previous immutable secrets to keep. Defaults to 2.
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32       `json:"revisionHistoryLimit,omitempty"`
+	Replication          *Replication `json:"replication,omitempty"`
+}
+
+// Condition types used in SecretClaimStatus.Conditions
+const (
+	// ConditionReady is true if the secret exists and matches the claim
+	ConditionReady string = "Ready"
+	// ConditionIssued is true if a valid certificate has been issued for an X.509 claim
+	ConditionIssued string = "Issued"
+	// ConditionRotationDue is true if the certificate of an X.509 claim is in the last third of its
+	// validity period and will be reissued
+	ConditionRotationDue string = "RotationDue"
+	// ConditionReplicated is true if the secret has been replicated to all namespaces selected by the claim
+	ConditionReplicated string = "Replicated"
+)
+
+// CertificateStatus contains details of the currently issued certificate for X.509 claims.
+type CertificateStatus struct {
+	NotAfter metav1.Time `json:"notAfter"`
+	// Hex-encoded serial number
+	SerialNumber string `json:"serialNumber"`
+	// Hex-encoded SHA-256 fingerprint of the DER-encoded certificate
+	Fingerprint string `json:"fingerprint"`
+}
+
+type SecretClaimStatus struct {
+	// The generation of the claim which was last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Current state of the claim, see the Condition constants
+	// +listType=map
+	// +listMapKey=type
+	Conditions  []metav1.Condition `json:"conditions,omitempty"`
+	Certificate *CertificateStatus `json:"certificate,omitempty"`
+	// Time at which the current certificate was issued.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// Name of the secret currently generated for the claim
+	SecretName string `json:"secretName,omitempty"`
+	// Namespaces into which the secret is currently replicated
+	ReplicatedNamespaces []string `json:"replicatedNamespaces,omitempty"`
+	// Entropy of the tokens generated for each token field in bits
+	TokenEntropyBits map[string]int32 `json:"tokenEntropyBits,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=secc
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretName`,priority=1
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.certificate.notAfter`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SecretClaim creates and maintains a secret in its namespace.
+type SecretClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SecretClaimSpec   `json:"spec"`
+	Status SecretClaimStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+type SecretClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SecretClaim `json:"items"`
+}
@@ -0,0 +1,68 @@
+package v1beta1
+
+// DefaultRevisionHistoryLimit is the number of previous immutable secrets kept if RevisionHistoryLimit is not set.
+const DefaultRevisionHistoryLimit int32 = 2
+
+// DefaultPassphraseSeparator separates the words of passphrases if Separator is not set.
+const DefaultPassphraseSeparator = "-"
+
+// Defaults of OTPSpec
+const (
+	DefaultOTPDigits     int32 = 6
+	DefaultOTPPeriod     int32 = 30
+	DefaultOTPSeedLength int32 = 20
+)
+
+// Default sets the defaults of all unset optional fields of the claim.
+func (sc *SecretClaim) Default() {
+	spec := &sc.Spec
+	if spec.AdoptionPolicy == "" {
+		spec.AdoptionPolicy = AdoptionPolicyRefuse
+	}
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = DeletionPolicyDelete
+	}
+	for key, tokenSpec := range spec.CustomTokenFields {
+		if tokenSpec.Encoding != "passphrase" {
+			continue
+		}
+		if tokenSpec.Passphrase == nil {
+			tokenSpec.Passphrase = &PassphraseSpec{}
+			spec.CustomTokenFields[key] = tokenSpec
+		}
+		if tokenSpec.Passphrase.Separator == nil {
+			separator := DefaultPassphraseSeparator
+			tokenSpec.Passphrase.Separator = &separator
+		}
+		if tokenSpec.Passphrase.Capitalization == "" {
+			tokenSpec.Passphrase.Capitalization = CapitalizationLower
+		}
+	}
+	for key, otpSpec := range spec.OTPFields {
+		otpSpec.Default()
+		spec.OTPFields[key] = otpSpec
+	}
+	if spec.RevisionHistoryLimit == nil {
+		limit := DefaultRevisionHistoryLimit
+		spec.RevisionHistoryLimit = &limit
+	}
+}
+
+// Default sets the defaults of all unset optional fields of the spec.
+func (s *OTPSpec) Default() {
+	if s.Type == "" {
+		s.Type = OTPTypeTOTP
+	}
+	if s.Algorithm == "" {
+		s.Algorithm = OTPAlgorithmSHA1
+	}
+	if s.Digits == 0 {
+		s.Digits = DefaultOTPDigits
+	}
+	if s.Type == OTPTypeTOTP && s.Period == 0 {
+		s.Period = DefaultOTPPeriod
+	}
+	if s.SeedLength == 0 {
+		s.SeedLength = DefaultOTPSeedLength
+	}
+}
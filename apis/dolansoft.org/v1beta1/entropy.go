@@ -0,0 +1,123 @@
+package v1beta1
+
+import (
+	"fmt"
+	"math"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// TokenFieldEntropyBits is the entropy of the tokens generated for TokenFields.
+const TokenFieldEntropyBits = 128
+
+// uuidEntropyBits is the number of random bits in a version 4 UUID.
+const uuidEntropyBits = 122
+
+// uuidv7EntropyBits is the number of random bits in a version 7 UUID, the timestamp is not secret.
+const uuidv7EntropyBits = 74
+
+// ulidEntropyBits is the number of random bits in a ULID.
+const ulidEntropyBits = 80
+
+// EFFLargeWordListSize is the number of words in the EFF large word list used for passphrases by default.
+const EFFLargeWordListSize = 7776
+
+// effLargeWordList is the size of the EFF large word list. All of its words start with a letter.
+var effLargeWordList = WordListSize{Words: EFFLargeWordListSize, Capitalizable: EFFLargeWordListSize}
+
+// WordListSize is the size of a word list for passphrases.
+type WordListSize struct {
+	// Words is the number of words in the list.
+	Words int
+	// Capitalizable is the number of words which change in title case. Only these can be drawn in two ways with
+	// random capitalization.
+	Capitalizable int
+}
+
+// WordListSizes maps custom token fields of passphrases from word lists in ConfigMaps to the size of their list.
+// Fields whose list could not be read are missing.
+type WordListSizes map[string]WordListSize
+
+// EntropyBits returns the entropy of the tokens generated for the spec in bits. Prefix and suffix are fixed and do not
+// contribute to it. For passphrases from word lists in ConfigMaps wordList is the size of the list, it is ignored
+// otherwise.
+func (s *CustomTokenSpec) EntropyBits(wordList WordListSize) float64 {
+	switch s.Encoding {
+	case "uuid":
+		return uuidEntropyBits
+	case "uuidv7":
+		return uuidv7EntropyBits
+	case "ulid":
+		return ulidEntropyBits
+	case "characterset":
+		distinct := make(map[rune]bool)
+		for _, r := range s.CharacterSet {
+			distinct[r] = true
+		}
+		if len(distinct) == 0 {
+			return 0
+		}
+		return float64(s.Length) * math.Log2(float64(len(distinct)))
+	case "passwordpolicy":
+		if s.PasswordPolicy == nil {
+			return 0
+		}
+		return s.PasswordPolicy.entropyBits(s.Length)
+	case "passphrase":
+		list := effLargeWordList
+		if s.Passphrase != nil && s.Passphrase.WordListRef != nil {
+			list = wordList
+		}
+		if list.Words < 1 {
+			return 0
+		}
+		choices := list.Words
+		if s.Passphrase != nil && s.Passphrase.Capitalization == CapitalizationRandom {
+			choices += list.Capitalizable
+		}
+		return float64(s.Length) * math.Log2(float64(choices))
+	default:
+		return 8 * float64(s.Length)
+	}
+}
+
+// tokenEntropyBits returns the entropy of a custom token field in bits. It is unknown for passphrases whose word list
+// could not be read.
+func (s *SecretClaimSpec) tokenEntropyBits(key string, wordListSizes WordListSizes) (float64, bool) {
+	tokenSpec := s.CustomTokenFields[key]
+	if tokenSpec.Encoding != "passphrase" || tokenSpec.Passphrase == nil || tokenSpec.Passphrase.WordListRef == nil {
+		return tokenSpec.EntropyBits(WordListSize{}), true
+	}
+	size, ok := wordListSizes[key]
+	return tokenSpec.EntropyBits(size), ok
+}
+
+// TokenEntropyBits returns the entropy of each custom token field in whole bits, rounded down. Passphrases whose word
+// list could not be read are left out.
+func (s *SecretClaimSpec) TokenEntropyBits(wordListSizes WordListSizes) map[string]int32 {
+	if len(s.CustomTokenFields) == 0 {
+		return nil
+	}
+	bits := make(map[string]int32, len(s.CustomTokenFields))
+	for key := range s.CustomTokenFields {
+		if b, ok := s.tokenEntropyBits(key, wordListSizes); ok {
+			bits[key] = int32(math.Min(math.Floor(b), math.MaxInt32))
+		}
+	}
+	return bits
+}
+
+// ValidateMinEntropy rejects custom token fields with less than minBits bits of entropy unless they set
+// AllowLowEntropy. Passphrases whose word list could not be read are not checked.
+func (s *SecretClaimSpec) ValidateMinEntropy(minBits int32, wordListSizes WordListSizes) field.ErrorList {
+	var errs field.ErrorList
+	path := field.NewPath("spec", "customTokenFields")
+	for _, key := range sortedKeys(s.CustomTokenFields) {
+		tokenSpec := s.CustomTokenFields[key]
+		if bits, ok := s.tokenEntropyBits(key, wordListSizes); ok && bits < float64(minBits) && !tokenSpec.AllowLowEntropy {
+			errs = append(errs, field.Forbidden(path.Key(key),
+				fmt.Sprintf("tokens have %.1f bits of entropy, at least %d are required unless allowLowEntropy is set", bits, minBits)))
+		}
+	}
+	return errs
+}
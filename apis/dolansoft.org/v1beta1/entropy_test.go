@@ -0,0 +1,121 @@
+package v1beta1
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEntropyBits(t *testing.T) {
+	// Word lists in config maps have 1536 words, of which 512 change in title case. The size is ignored for all other
+	// tokens.
+	tests := []struct {
+		name string
+		spec CustomTokenSpec
+		want float64
+	}{
+		{"hex", CustomTokenSpec{Length: 16, Encoding: "hex"}, 128},
+		{"base64", CustomTokenSpec{Length: 32, Encoding: "base64"}, 256},
+		{"uuid", CustomTokenSpec{Encoding: "uuid"}, 122},
+		{"uuidv7", CustomTokenSpec{Encoding: "uuidv7"}, 74},
+		{"ulid", CustomTokenSpec{Encoding: "ulid"}, 80},
+		{"base58", CustomTokenSpec{Length: 32, Encoding: "base58"}, 256},
+		{"characterset", CustomTokenSpec{Length: 10, Encoding: "characterset", CharacterSet: "abcd"}, 20},
+		{"Duplicate characters", CustomTokenSpec{Length: 10, Encoding: "characterset", CharacterSet: "aabbccdd"}, 20},
+		{"Single character", CustomTokenSpec{Length: 10, Encoding: "characterset", CharacterSet: "a"}, 0},
+		{"passwordpolicy", CustomTokenSpec{Length: 10, Encoding: "passwordpolicy", PasswordPolicy: &PasswordPolicy{
+			Classes:               []CharacterClass{{Name: "digits", Characters: "0123"}, {Name: "letters", Characters: "abcd"}},
+			FirstCharacterClasses: []string{"letters"},
+		}}, 2 + 9*3},
+		{"passwordpolicy with minimum counts", CustomTokenSpec{Length: 2, Encoding: "passwordpolicy", PasswordPolicy: &PasswordPolicy{
+			Classes: []CharacterClass{{Name: "digits", Characters: "01", MinCount: 1}, {Name: "letters", Characters: "ab", MinCount: 1}},
+		}}, 3},
+		{"Long passwordpolicy", CustomTokenSpec{Length: 256, Encoding: "passwordpolicy", PasswordPolicy: &PasswordPolicy{
+			Classes: []CharacterClass{{Name: "hex", Characters: "0123456789abcdef"}},
+		}}, 1024},
+		{"passphrase", CustomTokenSpec{Length: 6, Encoding: "passphrase"}, 6 * math.Log2(EFFLargeWordListSize)},
+		{"passphrase with random capitalization", CustomTokenSpec{Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+			Capitalization: CapitalizationRandom,
+		}}, 6*math.Log2(EFFLargeWordListSize) + 6},
+		{"passphrase from a config map", CustomTokenSpec{Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+			WordListRef: &corev1.ConfigMapKeySelector{Key: "words"},
+		}}, 6 * math.Log2(1536)},
+		{"passphrase from a config map with random capitalization", CustomTokenSpec{Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+			WordListRef: &corev1.ConfigMapKeySelector{Key: "words"}, Capitalization: CapitalizationRandom,
+		}}, 66},
+		{"Prefix does not count", CustomTokenSpec{Length: 1, Encoding: "raw", Prefix: "very long prefix"}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.EntropyBits(WordListSize{Words: 1536, Capitalizable: 512}); got != tt.want {
+				t.Errorf("EntropyBits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMinEntropy(t *testing.T) {
+	passphrase := CustomTokenSpec{Length: 4, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+		WordListRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "words"}, Key: "list"},
+	}}
+	tests := []struct {
+		name          string
+		spec          SecretClaimSpec
+		wordListSizes WordListSizes
+		minBits       int32
+		want          []string
+	}{
+		{"Disabled", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{"pin": {Length: 4, Encoding: "characterset", CharacterSet: "0123456789"}},
+		}, nil, 0, nil},
+		{"Weak token", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"pin":      {Length: 6, Encoding: "characterset", CharacterSet: "0123456789"},
+				"password": {Length: 16, Encoding: "base64"},
+			},
+		}, nil, 64, []string{"FieldValueForbidden spec.customTokenFields[pin]"}},
+		{"Weak token allowed", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"pin": {Length: 6, Encoding: "characterset", CharacterSet: "0123456789", AllowLowEntropy: true},
+			},
+		}, nil, 64, nil},
+		{"Exactly the minimum", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{"key": {Length: 8, Encoding: "raw"}},
+		}, nil, 64, nil},
+		{"Weak word list", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{"passphrase": passphrase},
+		}, WordListSizes{"passphrase": {Words: 1024}}, 64, []string{"FieldValueForbidden spec.customTokenFields[passphrase]"}},
+		{"Strong word list", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{"passphrase": passphrase},
+		}, WordListSizes{"passphrase": {Words: 1 << 16}}, 64, nil},
+		{"Unreadable word list", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{"passphrase": passphrase},
+		}, nil, 64, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorFields(tt.spec.ValidateMinEntropy(tt.minBits, tt.wordListSizes)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateMinEntropy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenEntropyBits(t *testing.T) {
+	spec := SecretClaimSpec{CustomTokenFields: map[string]CustomTokenSpec{
+		"pin": {Length: 6, Encoding: "characterset", CharacterSet: "0123456789"},
+		"id":  {Encoding: "uuid"},
+		"passphrase": {Length: 4, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+			WordListRef: &corev1.ConfigMapKeySelector{Key: "list"},
+		}},
+		"unreadable": {Length: 4, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+			WordListRef: &corev1.ConfigMapKeySelector{Key: "missing"},
+		}},
+	}}
+	want := map[string]int32{"pin": 19, "id": 122, "passphrase": 40}
+	if got := spec.TokenEntropyBits(WordListSizes{"passphrase": {Words: 1024}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenEntropyBits() = %v, want %v", got, want)
+	}
+}
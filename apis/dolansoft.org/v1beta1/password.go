@@ -0,0 +1,315 @@
+package v1beta1
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// MaxCharacterClasses is the largest number of classes a PasswordPolicy may have.
+const MaxCharacterClasses = 8
+
+// PredefinedCharacterClasses are the characters of classes of a PasswordPolicy which don't list their characters.
+var PredefinedCharacterClasses = map[string]string{
+	"lowercase": "abcdefghijklmnopqrstuvwxyz",
+	"uppercase": "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"digits":    "0123456789",
+	"symbols":   "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+}
+
+// ClassRunes returns the distinct characters of each class in the order of Classes, without the excluded characters.
+func (p *PasswordPolicy) ClassRunes() [][]rune {
+	excluded := make(map[rune]bool)
+	for _, r := range p.ExcludedCharacters {
+		excluded[r] = true
+	}
+	classes := make([][]rune, len(p.Classes))
+	for i, class := range p.Classes {
+		characters := class.Characters
+		if characters == "" {
+			characters = PredefinedCharacterClasses[class.Name]
+		}
+		seen := make(map[rune]bool)
+		for _, r := range characters {
+			if !excluded[r] && !seen[r] {
+				seen[r] = true
+				classes[i] = append(classes[i], r)
+			}
+		}
+	}
+	return classes
+}
+
+// classMask returns a bit mask of the named classes, all classes if names is empty.
+func (p *PasswordPolicy) classMask(names []string) uint32 {
+	if len(names) == 0 {
+		return 1<<len(p.Classes) - 1
+	}
+	var mask uint32
+	for _, name := range names {
+		for i, class := range p.Classes {
+			if class.Name == name {
+				mask |= 1 << i
+			}
+		}
+	}
+	return mask
+}
+
+// PositionClasses returns bit masks of the classes allowed for the first and last character.
+func (p *PasswordPolicy) PositionClasses() (first, last uint32) {
+	return p.classMask(p.FirstCharacterClasses), p.classMask(p.LastCharacterClasses)
+}
+
+// entropyBits returns the entropy of tokens of the given length. Tokens are drawn uniformly from all tokens satisfying
+// the policy, so it is the binary logarithm of their number.
+func (p *PasswordPolicy) entropyBits(length int32) float64 {
+	if length < 1 || length > MaxPasswordPolicyLength || len(p.Classes) > MaxCharacterClasses {
+		return 0
+	}
+	return log2(NewPasswordCounts(p, int(length)).Total())
+}
+
+// log2 returns the binary logarithm of n, which may exceed the range of a float64. It is 0 if n is not positive.
+func log2(n *big.Int) float64 {
+	if n.Sign() <= 0 {
+		return 0
+	}
+	shift := n.BitLen() - 64
+	if shift < 0 {
+		shift = 0
+	}
+	top, _ := new(big.Float).SetInt(new(big.Int).Rsh(n, uint(shift))).Float64()
+	return math.Log2(top) + float64(shift)
+}
+
+// Validate checks if tokens of the given length can satisfy the policy.
+func (p *PasswordPolicy) Validate(fldPath *field.Path, length int32) field.ErrorList {
+	var errs field.ErrorList
+	classesPath := fldPath.Child("classes")
+	if len(p.Classes) == 0 {
+		errs = append(errs, field.Required(classesPath, "at least one character class is required"))
+		return errs
+	}
+	if len(p.Classes) > MaxCharacterClasses {
+		errs = append(errs, field.TooMany(classesPath, len(p.Classes), MaxCharacterClasses))
+		return errs
+	}
+	names := make(map[string]bool)
+	owner := make(map[rune]string)
+	var minCounts int64
+	for i, runes := range p.ClassRunes() {
+		class := p.Classes[i]
+		classPath := classesPath.Index(i)
+		if class.Name == "" {
+			errs = append(errs, field.Required(classPath.Child("name"), ""))
+		} else if names[class.Name] {
+			errs = append(errs, field.Duplicate(classPath.Child("name"), class.Name))
+		}
+		names[class.Name] = true
+		if _, ok := PredefinedCharacterClasses[class.Name]; class.Characters == "" && !ok {
+			errs = append(errs, field.Required(classPath.Child("characters"), "only predefined classes may omit their characters"))
+		} else if len(runes) == 0 {
+			errs = append(errs, field.Invalid(classPath.Child("characters"), class.Characters, "no characters left after removing excludedCharacters"))
+		}
+		for _, r := range runes {
+			if other, ok := owner[r]; ok && other != class.Name {
+				errs = append(errs, field.Invalid(classPath.Child("characters"), class.Characters,
+					fmt.Sprintf("shares the character %q with class %s", r, other)))
+				break
+			}
+			owner[r] = class.Name
+		}
+		if class.MinCount < 0 {
+			errs = append(errs, field.Invalid(classPath.Child("minCount"), class.MinCount, "must not be negative"))
+		}
+		minCounts += int64(class.MinCount)
+	}
+	for _, positional := range []struct {
+		name    string
+		classes []string
+	}{{"firstCharacterClasses", p.FirstCharacterClasses}, {"lastCharacterClasses", p.LastCharacterClasses}} {
+		for i, name := range positional.classes {
+			if !names[name] {
+				errs = append(errs, field.NotFound(fldPath.Child(positional.name).Index(i), name))
+			}
+		}
+	}
+	if len(errs) > 0 || length < 1 {
+		return errs
+	}
+	if minCounts > int64(length) {
+		errs = append(errs, field.Invalid(classesPath, minCounts, fmt.Sprintf("minimum counts add up to more than the length of %d", length)))
+	} else if !p.satisfiable(length) {
+		errs = append(errs, field.Invalid(fldPath, length, "minimum counts cannot be satisfied with the first and last character restrictions"))
+	}
+	return errs
+}
+
+// satisfiable checks if the minimum counts can be met given the restrictions of the first and last character. By
+// Hall's theorem this is the case if every set of classes requires at most as many characters as there are positions
+// allowing one of them.
+func (p *PasswordPolicy) satisfiable(length int32) bool {
+	first, last := p.PositionClasses()
+	if length == 1 {
+		first &= last
+		last = 0
+		if first == 0 {
+			return false
+		}
+	}
+	for set := uint32(1); set < 1<<len(p.Classes); set++ {
+		var required, positions int64
+		for i, class := range p.Classes {
+			if set&(1<<i) != 0 {
+				required += int64(class.MinCount)
+			}
+		}
+		if set&first != 0 {
+			positions++
+		}
+		if length >= 2 {
+			positions += int64(length) - 2
+			if set&last != 0 {
+				positions++
+			}
+		}
+		if required > positions {
+			return false
+		}
+	}
+	return true
+}
+
+// States of the first and last position while placing the classes of a token
+const (
+	FirstOpen = 1 << iota
+	LastOpen
+)
+
+// PasswordCounts counts the tokens satisfying a policy. The classes with a minimum count are placed one after another
+// and all other classes are pooled into a tail, which has no restrictions besides the first and last position.
+type PasswordCounts struct {
+	policy  *PasswordPolicy
+	classes [][]rune
+	// Classes allowed for the first and the last position
+	firstMask, lastMask uint32
+	// Constrained holds the indices of the classes with a minimum count in the order they are placed.
+	Constrained []int
+	// Number of characters of the tail at all, the first and the last position
+	tail, tailFirst, tailLast int64
+	// Open positions and number of positions in between of the token as a whole
+	initial, middle int
+	// counts[j][state][m] is the number of ways to fill m positions in the middle and the open first and last
+	// position with constrained classes j and later and the tail.
+	counts [][4][]*big.Int
+}
+
+// NewPasswordCounts counts the tokens of the given length, which must be at least 1.
+func NewPasswordCounts(policy *PasswordPolicy, length int) *PasswordCounts {
+	p := &PasswordCounts{policy: policy, classes: policy.ClassRunes(), initial: FirstOpen | LastOpen, middle: length - 2}
+	p.firstMask, p.lastMask = policy.PositionClasses()
+	if length == 1 {
+		p.firstMask &= p.lastMask
+		p.lastMask = p.firstMask
+		p.initial, p.middle = FirstOpen, 0
+	}
+	for i, class := range policy.Classes {
+		if class.MinCount > 0 {
+			p.Constrained = append(p.Constrained, i)
+			continue
+		}
+		p.tail += int64(len(p.classes[i]))
+		if p.firstMask&(1<<i) != 0 {
+			p.tailFirst += int64(len(p.classes[i]))
+		}
+		if p.lastMask&(1<<i) != 0 {
+			p.tailLast += int64(len(p.classes[i]))
+		}
+	}
+	p.counts = make([][4][]*big.Int, len(p.Constrained)+1)
+	for state := 0; state < 4; state++ {
+		tail := make([]*big.Int, p.middle+1)
+		tail[0] = big.NewInt(1)
+		if state&FirstOpen != 0 {
+			tail[0].Mul(tail[0], big.NewInt(p.tailFirst))
+		}
+		if state&LastOpen != 0 {
+			tail[0].Mul(tail[0], big.NewInt(p.tailLast))
+		}
+		for m := 1; m <= p.middle; m++ {
+			tail[m] = new(big.Int).Mul(tail[m-1], big.NewInt(p.tail))
+		}
+		p.counts[len(p.Constrained)][state] = tail
+	}
+	for j := len(p.Constrained) - 1; j >= 0; j-- {
+		for state := 0; state < 4; state++ {
+			p.counts[j][state] = make([]*big.Int, p.middle+1)
+			for m := range p.counts[j][state] {
+				if j == 0 && (state != p.initial || m != p.middle) {
+					continue // Only the token as a whole is placed starting with the first class
+				}
+				total := new(big.Int)
+				p.Choices(j, state, m, func(weight *big.Int, _, _ int) bool {
+					total.Add(total, weight)
+					return false
+				})
+				p.counts[j][state][m] = total
+			}
+		}
+	}
+	return p
+}
+
+// Initial returns the open positions and the number of positions in between of the token as a whole.
+func (p *PasswordCounts) Initial() (state, middle int) {
+	return p.initial, p.middle
+}
+
+// Total returns the number of tokens satisfying the policy.
+func (p *PasswordCounts) Total() *big.Int {
+	return p.counts[0][p.initial][p.middle]
+}
+
+// Count returns the number of ways to fill m positions in the middle and the open positions of state with constrained
+// classes j and later and the tail.
+func (p *PasswordCounts) Count(j, state, m int) *big.Int {
+	return p.counts[j][state][m]
+}
+
+// Choices calls fn for every way constrained class j can take the first and last position, given they are still
+// open, and n of the m remaining positions in the middle. The weight is the number of tokens with this choice. It
+// stops once fn returns true.
+func (p *PasswordCounts) Choices(j, state, m int, fn func(weight *big.Int, newState, n int) bool) {
+	class := p.Constrained[j]
+	size := int64(len(p.classes[class]))
+	minCount := int(p.policy.Classes[class].MinCount)
+	for _, taken := range []int{0, FirstOpen, LastOpen, FirstOpen | LastOpen} {
+		if taken&state != taken || taken&FirstOpen != 0 && p.firstMask&(1<<class) == 0 ||
+			taken&LastOpen != 0 && p.lastMask&(1<<class) == 0 {
+			continue
+		}
+		ends := bits.OnesCount(uint(taken))
+		n := minCount - ends
+		if n < 0 {
+			n = 0
+		}
+		if n > m {
+			continue
+		}
+		// The number of ways to pick n of m positions and their characters and the characters at the ends
+		ways := new(big.Int).Binomial(int64(m), int64(n))
+		ways.Mul(ways, new(big.Int).Exp(big.NewInt(size), big.NewInt(int64(n+ends)), nil))
+		for ; n <= m; n++ {
+			rest := p.counts[j+1][state&^taken][m-n]
+			if rest.Sign() > 0 && fn(new(big.Int).Mul(ways, rest), state&^taken, n) {
+				return
+			}
+			ways.Mul(ways, big.NewInt(size*int64(m-n)))
+			ways.Quo(ways, big.NewInt(int64(n+1)))
+		}
+	}
+}
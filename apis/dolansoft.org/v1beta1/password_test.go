@@ -0,0 +1,58 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	strict := PasswordPolicy{
+		Classes: []CharacterClass{
+			{Name: "lowercase", MinCount: 1},
+			{Name: "uppercase", MinCount: 1},
+			{Name: "digits", MinCount: 1},
+			{Name: "special", Characters: "!#%", MinCount: 1},
+		},
+		ExcludedCharacters:    "0O1lI",
+		FirstCharacterClasses: []string{"lowercase", "uppercase"},
+	}
+	tests := []struct {
+		name   string
+		policy PasswordPolicy
+		length int32
+		want   []string
+	}{
+		{"Complex policy", strict, 12, nil},
+		{"Minimum counts fill the length", strict, 4, nil},
+		{"No classes", PasswordPolicy{}, 12, []string{"FieldValueRequired passwordPolicy.classes"}},
+		{"Unknown predefined class", PasswordPolicy{Classes: []CharacterClass{{Name: "emoji"}}}, 12,
+			[]string{"FieldValueRequired passwordPolicy.classes[0].characters"}},
+		{"Duplicate class", PasswordPolicy{Classes: []CharacterClass{{Name: "digits"}, {Name: "digits", Characters: "abc"}}}, 12,
+			[]string{"FieldValueDuplicate passwordPolicy.classes[1].name"}},
+		{"Shared characters", PasswordPolicy{Classes: []CharacterClass{{Name: "digits"}, {Name: "odd", Characters: "13579"}}}, 12,
+			[]string{"FieldValueInvalid passwordPolicy.classes[1].characters"}},
+		{"All characters excluded", PasswordPolicy{Classes: []CharacterClass{{Name: "digits"}}, ExcludedCharacters: "0123456789"}, 12,
+			[]string{"FieldValueInvalid passwordPolicy.classes[0].characters"}},
+		{"Unknown first class", PasswordPolicy{Classes: []CharacterClass{{Name: "digits"}}, FirstCharacterClasses: []string{"letters"}}, 12,
+			[]string{"FieldValueNotFound passwordPolicy.firstCharacterClasses[0]"}},
+		{"Minimum counts exceed length", strict, 3, []string{"FieldValueInvalid passwordPolicy.classes"}},
+		{"Minimum counts conflict with first character", PasswordPolicy{
+			Classes:               []CharacterClass{{Name: "lowercase"}, {Name: "digits", MinCount: 4}},
+			FirstCharacterClasses: []string{"lowercase"},
+		}, 4, []string{"FieldValueInvalid passwordPolicy"}},
+		{"Single character with disjoint positions", PasswordPolicy{
+			Classes:               []CharacterClass{{Name: "lowercase"}, {Name: "digits"}},
+			FirstCharacterClasses: []string{"lowercase"},
+			LastCharacterClasses:  []string{"digits"},
+		}, 1, []string{"FieldValueInvalid passwordPolicy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorFields(tt.policy.Validate(field.NewPath("passwordPolicy"), tt.length)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Evaluate checks a claim against the policy and returns all violations. The entropy of passphrases whose word list
+// could not be read is not checked.
+func (p *SecretClaimPolicy) Evaluate(sc *SecretClaim, wordListSizes WordListSizes) field.ErrorList {
+	var errs field.ErrorList
+	spec := &sc.Spec
+	specPath := field.NewPath("spec")
+	policy := fmt.Sprintf("SecretClaimPolicy %s", p.Name)
+
+	if p.Spec.MinTokenEntropyBits != nil {
+		minBits := float64(*p.Spec.MinTokenEntropyBits)
+		if TokenFieldEntropyBits < minBits {
+			for i := range spec.TokenFields {
+				errs = append(errs, field.Forbidden(specPath.Child("tokenFields").Index(i),
+					fmt.Sprintf("tokens have %d bits of entropy, %s requires at least %d", TokenFieldEntropyBits, policy, *p.Spec.MinTokenEntropyBits)))
+			}
+		}
+		for _, key := range sortedKeys(spec.CustomTokenFields) {
+			if bits, ok := spec.tokenEntropyBits(key, wordListSizes); ok && bits < minBits {
+				errs = append(errs, field.Forbidden(specPath.Child("customTokenFields").Key(key),
+					fmt.Sprintf("tokens have %.1f bits of entropy, %s requires at least %d", bits, policy, *p.Spec.MinTokenEntropyBits)))
+			}
+		}
+	}
+	if len(p.Spec.AllowedEncodings) > 0 {
+		for _, key := range sortedKeys(spec.CustomTokenFields) {
+			encoding := spec.CustomTokenFields[key].Encoding
+			allowed := false
+			for _, e := range p.Spec.AllowedEncodings {
+				allowed = allowed || e == encoding
+			}
+			if !allowed {
+				errs = append(errs, field.Forbidden(specPath.Child("customTokenFields").Key(key).Child("encoding"),
+					fmt.Sprintf("encoding %s is not allowed by %s", encoding, policy)))
+			}
+		}
+	}
+	if p.Spec.AllowFixedFields != nil && !*p.Spec.AllowFixedFields && len(spec.FixedFields) > 0 {
+		errs = append(errs, field.Forbidden(specPath.Child("fixedFields"), fmt.Sprintf("fixed fields are not allowed by %s", policy)))
+	}
+
+	if x := spec.X509Claim; x != nil {
+		x509Path := specPath.Child("x509")
+		if p.Spec.MaxCertificateLifetime != "" {
+			maxLifetime, err := ParseRotateEvery(p.Spec.MaxCertificateLifetime)
+			if err != nil {
+				errs = append(errs, field.InternalError(x509Path.Child("rotateEvery"), fmt.Errorf("%s has an invalid maxCertificateLifetime: %w", policy, err)))
+			} else if x.RotateEvery == "" {
+				errs = append(errs, field.Required(x509Path.Child("rotateEvery"),
+					fmt.Sprintf("%s requires certificates to expire within %s", policy, p.Spec.MaxCertificateLifetime)))
+			} else if lifetime, err := ParseRotateEvery(x.RotateEvery); err == nil && lifetime > maxLifetime {
+				errs = append(errs, field.Forbidden(x509Path.Child("rotateEvery"),
+					fmt.Sprintf("%s requires certificates to expire within %s", policy, p.Spec.MaxCertificateLifetime)))
+			}
+		}
+		if len(p.Spec.AllowedCASecretNames) > 0 {
+			allowed := false
+			for _, name := range p.Spec.AllowedCASecretNames {
+				allowed = allowed || x.CASecretName != "" && name == x.CASecretName
+			}
+			if !allowed {
+				errs = append(errs, field.Forbidden(x509Path.Child("caSecretName"),
+					fmt.Sprintf("%s only allows certificates signed by %v", policy, p.Spec.AllowedCASecretNames)))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,77 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	minBits := int32(100)
+	noFixedFields := false
+	tests := []struct {
+		name   string
+		policy SecretClaimPolicySpec
+		spec   SecretClaimSpec
+		want   []string
+	}{
+		{"Empty policy", SecretClaimPolicySpec{}, SecretClaimSpec{
+			FixedFields:       map[string]string{"user": "admin"},
+			CustomTokenFields: map[string]CustomTokenSpec{"pin": {Length: 1, Encoding: "hex"}},
+		}, nil},
+		{"Sufficient entropy", SecretClaimPolicySpec{MinTokenEntropyBits: &minBits}, SecretClaimSpec{
+			TokenFields:       []string{"token"},
+			CustomTokenFields: map[string]CustomTokenSpec{"id": {Encoding: "uuid"}},
+		}, nil},
+		{"Insufficient entropy", SecretClaimPolicySpec{MinTokenEntropyBits: &minBits}, SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"pin":      {Length: 6, Encoding: "characterset", CharacterSet: "0123456789"},
+				"password": {Length: 32, Encoding: "base64"},
+			},
+		}, []string{"FieldValueForbidden spec.customTokenFields[pin]"}},
+		{"Passphrase from a small word list", SecretClaimPolicySpec{MinTokenEntropyBits: &minBits}, SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"passphrase": {Length: 8, Encoding: "passphrase", Passphrase: &PassphraseSpec{WordListRef: &corev1.ConfigMapKeySelector{Key: "list"}}},
+				"unreadable": {Length: 8, Encoding: "passphrase", Passphrase: &PassphraseSpec{WordListRef: &corev1.ConfigMapKeySelector{Key: "missing"}}},
+			},
+		}, []string{"FieldValueForbidden spec.customTokenFields[passphrase]"}},
+		{"Allowed encodings", SecretClaimPolicySpec{AllowedEncodings: []string{"hex", "base64"}}, SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"a": {Length: 16, Encoding: "hex"},
+				"b": {Length: 16, Encoding: "raw"},
+			},
+		}, []string{"FieldValueForbidden spec.customTokenFields[b].encoding"}},
+		{"Fixed fields forbidden", SecretClaimPolicySpec{AllowFixedFields: &noFixedFields}, SecretClaimSpec{
+			FixedFields: map[string]string{"user": "admin"},
+		}, []string{"FieldValueForbidden spec.fixedFields"}},
+		{"Certificate lifetime within limit", SecretClaimPolicySpec{MaxCertificateLifetime: "90d"}, SecretClaimSpec{
+			X509Claim: &X509Claim{RotateEvery: "30d"},
+		}, nil},
+		{"Certificate lifetime too long", SecretClaimPolicySpec{MaxCertificateLifetime: "90d"}, SecretClaimSpec{
+			X509Claim: &X509Claim{RotateEvery: "365d"},
+		}, []string{"FieldValueForbidden spec.x509.rotateEvery"}},
+		{"Certificate without expiry", SecretClaimPolicySpec{MaxCertificateLifetime: "90d"}, SecretClaimSpec{
+			X509Claim: &X509Claim{},
+		}, []string{"FieldValueRequired spec.x509.rotateEvery"}},
+		{"Invalid policy lifetime", SecretClaimPolicySpec{MaxCertificateLifetime: "soon"}, SecretClaimSpec{
+			X509Claim: &X509Claim{RotateEvery: "30d"},
+		}, []string{"InternalError spec.x509.rotateEvery"}},
+		{"Allowed CA", SecretClaimPolicySpec{AllowedCASecretNames: []string{"ca"}}, SecretClaimSpec{
+			X509Claim: &X509Claim{CASecretName: "ca"},
+		}, nil},
+		{"Self-signed with allowed CAs", SecretClaimPolicySpec{AllowedCASecretNames: []string{"ca"}}, SecretClaimSpec{
+			X509Claim: &X509Claim{},
+		}, []string{"FieldValueForbidden spec.x509.caSecretName"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &SecretClaimPolicy{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: tt.policy}
+			got := errorFields(policy.Evaluate(&SecretClaim{Spec: tt.spec}, WordListSizes{"passphrase": {Words: 1024}}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,58 +1,428 @@
 package v1beta1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 const (
 	GroupName string = "dolansoft.org"
 	Kind      string = "SecretClaim"
 	Version   string = "v1beta1"
 	Plural    string = "secretclaims"
-	Singluar  string = "secretclaim"
+	Singular  string = "secretclaim"
+	// Deprecated: use Singular
+	Singluar  string = Singular
 	ShortName string = "secc"
 	Name      string = Plural + "." + GroupName
 )
 
+// The validation rules of SecretClaims in v1beta1 ratchet: claims created before validation existed must stay
+// updatable, for example to add or remove finalizers. Every rule exists twice, once checked on updates which change the
+// validated value (oldSelf == self || ...) and once checked when the value is created (optionalOldSelf). Numeric bounds
+// are rules as well. Bounds of strings, enums and lists stay OpenAPI bounds, which the API server ratchets itself, as
+// comparing them in rules inside the field maps exceeds the cost budget of CEL.
+
+// X509Claim is a claim for an X.509 certificate
 type X509Claim struct {
-	CASecretName         string   `json:"caSecretName"`
-	IsCA                 bool     `json:"isCA"`
-	CommonName           string   `json:"commonName"`
-	RotateEvery          string   `json:"rotateEvery"`
-	ServiceNames         []string `json:"serviceNames"`
-	ExtraNames           []string `json:"extraNames"`
-	LegacySEC1PrivateKey bool     `json:"legacySEC1PrivateKey"`
+	// Name of the secret storing the CA. Must be in the same namespace as this claim. If unset and isCA is true the
+	// certificate is a root CA.
+	// +optional
+	CASecretName string `json:"caSecretName"`
+	// Determines if the certificate is a CA. Defaults to false.
+	// +optional
+	IsCA bool `json:"isCA"`
+	// Specifies a custom common name. Defaults to the claim name if unset.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="oldSelf == self || size(self) <= 64",message="must be at most 64 characters"
+	// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || size(self) <= 64",message="must be at most 64 characters",optionalOldSelf=true
+	CommonName string `json:"commonName"`
+	// Determines the validity period of the certificate. If unset the certificate is eternally valid. If the
+	// certificate is nearing expiration it is reissued automatically.
+	// +optional
+	RotateEvery string `json:"rotateEvery"`
+	// List of service names to be included in the subject alternative names of the certificate. Services need to be
+	// in the same namespace as the claim. Specifying services here is preferred over specifying them in extraNames as
+	// all valid forms (with/without namespace and cluster domain) are automatically included and the claim is
+	// namespace-independent.
+	// +optional
+	ServiceNames []string `json:"serviceNames"`
+	// Extra DNS names to be included in the subject alternative names.
+	// +optional
+	ExtraNames []string `json:"extraNames"`
+	// If set to true, the private key is generated in the legacy SEC1 encoding.
+	// +optional
+	LegacySEC1PrivateKey bool `json:"legacySEC1PrivateKey"`
 }
 
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || (self.encoding in ['uuid', 'uuidv7', 'ulid'] || (has(self.length) && self.length >= 1))",message="length needs to be at least 1"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || (self.encoding in ['uuid', 'uuidv7', 'ulid'] || (has(self.length) && self.length >= 1))",message="length needs to be at least 1",optionalOldSelf=true
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || (self.encoding != 'z85' || (has(self.length) && self.length % 4 == 0))",message="length needs to be a multiple of 4 for the z85 encoding"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || (self.encoding != 'z85' || (has(self.length) && self.length % 4 == 0))",message="length needs to be a multiple of 4 for the z85 encoding",optionalOldSelf=true
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || (self.encoding != 'characterset' || (has(self.characterSet) && size(self.characterSet) > 0))",message="characterSet is required for the characterset encoding"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || (self.encoding != 'characterset' || (has(self.characterSet) && size(self.characterSet) > 0))",message="characterSet is required for the characterset encoding",optionalOldSelf=true
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || ((self.encoding == 'passwordpolicy') == has(self.passwordPolicy))",message="passwordPolicy is required for and only allowed with the passwordpolicy encoding"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || ((self.encoding == 'passwordpolicy') == has(self.passwordPolicy))",message="passwordPolicy is required for and only allowed with the passwordpolicy encoding",optionalOldSelf=true
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || (self.encoding == 'passphrase' || !has(self.passphrase))",message="passphrase is only allowed with the passphrase encoding"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || (self.encoding == 'passphrase' || !has(self.passphrase))",message="passphrase is only allowed with the passphrase encoding",optionalOldSelf=true
 type CustomTokenSpec struct {
-	Length       int32  `json:"length"`
-	Encoding     string `json:"encoding"`
+	// Length of the token which should be generated. This is the number of bytes for the base64, base32, crockford,
+	// base58, z85, hex and raw encodings, the number of characters for the characterset and passwordpolicy encodings
+	// (at most 256 for passwordpolicy) and the number of words for the passphrase encoding. It is ignored for the uuid,
+	// uuidv7 and ulid encodings.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="oldSelf == self || self <= 1048576",message="must be at most 1048576"
+	// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || self <= 1048576",message="must be at most 1048576",optionalOldSelf=true
+	Length int32 `json:"length"`
+	// Encoding to use. base32 is the padded RFC 4648 encoding, crockford is Crockford's base32 without padding,
+	// base58 uses the Bitcoin alphabet (at most 256 bytes) and z85 is the ZeroMQ base85 encoding, which requires the
+	// length to be a multiple of 4. ulid and uuidv7 generate time-ordered identifiers.
+	// +kubebuilder:validation:Enum=base64;base64url;hex;upperhex;characterset;raw;uuid;passwordpolicy;passphrase;base32;crockford;base58;z85;ulid;uuidv7
+	Encoding string `json:"encoding"`
+	// All characters (unicode code points) in this string can be used to make up the token. Only used in the
+	// characterset encoding.
 	CharacterSet string `json:"characterSet,omitempty"`
-	Prefix       string `json:"prefix,omitempty"`
-	Suffix       string `json:"suffix,omitempty"`
+	// Prefix to put before the generated token.
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix to put after the generated token.
+	Suffix string `json:"suffix,omitempty"`
+	// AllowLowEntropy exempts the token from the minimum entropy configured on the controller, for example for
+	// PINs required by a legacy system. It does not exempt it from SecretClaimPolicies.
+	AllowLowEntropy bool `json:"allowLowEntropy,omitempty"`
+	// PasswordPolicy describes the characters the token is made of for the passwordpolicy encoding.
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy,omitempty"`
+	// Passphrase configures the passphrase encoding.
+	// +optional
+	Passphrase *PassphraseSpec `json:"passphrase,omitempty"`
+}
+
+// Capitalization of the words of a passphrase.
+// +kubebuilder:validation:Enum=lower;title;upper;random
+type Capitalization string
+
+const (
+	// CapitalizationLower writes all words in lower case. This is the default.
+	CapitalizationLower Capitalization = "lower"
+	// CapitalizationTitle capitalizes the first letter of every word.
+	CapitalizationTitle Capitalization = "title"
+	// CapitalizationUpper writes all words in upper case.
+	CapitalizationUpper Capitalization = "upper"
+	// CapitalizationRandom capitalizes the first letter of each word with a probability of one half. This adds
+	// entropy only for words which change in title case, one bit per word if all of them do.
+	CapitalizationRandom Capitalization = "random"
+)
+
+// PassphraseSpec describes passphrases made up of words drawn from a word list.
+type PassphraseSpec struct {
+	// Separator between the words. Defaults to "-".
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+	// Capitalization of the words. Defaults to lower.
+	// +optional
+	Capitalization Capitalization `json:"capitalization,omitempty"`
+	// WordListRef selects a key of a ConfigMap in the namespace of the claim containing the word list, one word per
+	// line. Lines of diceware lists like "11111 abacus" are supported, empty lines and lines starting with # are
+	// ignored. Defaults to the EFF large word list. The entropy of the passphrases is computed from the number of
+	// distinct words in the list.
+	// +optional
+	WordListRef *corev1.ConfigMapKeySelector `json:"wordListRef,omitempty"`
+}
+
+// CharacterClass is a named set of characters of a PasswordPolicy.
+type CharacterClass struct {
+	// Name of the class. If Characters is empty, it must be one of the predefined classes lowercase, uppercase,
+	// digits or symbols.
+	Name string `json:"name"`
+	// Characters (unicode code points) of the class. Classes must not share characters.
+	// +optional
+	Characters string `json:"characters,omitempty"`
+	// MinCount is the number of characters of the token which at least need to be from this class.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="oldSelf == self || self >= 0",message="must be at least 0"
+	// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || self >= 0",message="must be at least 0",optionalOldSelf=true
+	MinCount int32 `json:"minCount,omitempty"`
+}
+
+// PasswordPolicy describes tokens for systems with password complexity rules. Every character of the token is
+// picked from the union of all classes and tokens are drawn uniformly from all tokens satisfying the policy.
+type PasswordPolicy struct {
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	Classes []CharacterClass `json:"classes"`
+	// ExcludedCharacters are never used, for example ambiguous characters like 0O1lI.
+	// +optional
+	ExcludedCharacters string `json:"excludedCharacters,omitempty"`
+	// FirstCharacterClasses restricts the first character to the named classes, for example to not start with a
+	// digit. All classes are allowed if empty.
+	// +optional
+	FirstCharacterClasses []string `json:"firstCharacterClasses,omitempty"`
+	// LastCharacterClasses restricts the last character to the named classes. All classes are allowed if empty.
+	// +optional
+	LastCharacterClasses []string `json:"lastCharacterClasses,omitempty"`
+}
+
+// OTPType is the kind of one-time passwords generated from an OTP seed.
+// +kubebuilder:validation:Enum=totp;hotp
+type OTPType string
+
+const (
+	// OTPTypeTOTP generates time-based one-time passwords (RFC 6238). This is the default.
+	OTPTypeTOTP OTPType = "totp"
+	// OTPTypeHOTP generates counter-based one-time passwords (RFC 4226).
+	OTPTypeHOTP OTPType = "hotp"
+)
+
+// OTPAlgorithm is the HMAC hash function of one-time passwords.
+// +kubebuilder:validation:Enum=SHA1;SHA256;SHA512
+type OTPAlgorithm string
+
+const (
+	// OTPAlgorithmSHA1 is the default and the only algorithm supported by all authenticator apps.
+	OTPAlgorithmSHA1   OTPAlgorithm = "SHA1"
+	OTPAlgorithmSHA256 OTPAlgorithm = "SHA256"
+	OTPAlgorithmSHA512 OTPAlgorithm = "SHA512"
+)
+
+// OTPSpec describes a seed for one-time passwords. The seed is stored base32-encoded without padding in the key of
+// the field and the otpauth:// provisioning URI in <key>.uri. If QRCode is set, a PNG image of a QR code of the URI is
+// stored in <key>.png. The URI and QR code are derived from the seed and updated if the other settings change.
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || (!has(self.period) || !has(self.type) || self.type == 'totp')",message="period is only allowed for totp"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || (!has(self.period) || !has(self.type) || self.type == 'totp')",message="period is only allowed for totp",optionalOldSelf=true
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || (!has(self.counter) || (has(self.type) && self.type == 'hotp'))",message="counter is only allowed for hotp"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || (!has(self.counter) || (has(self.type) && self.type == 'hotp'))",message="counter is only allowed for hotp",optionalOldSelf=true
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || ((!has(self.digits) || self.digits == 6 || self.digits == 8) && (!has(self.period) || self.period >= 1) && (!has(self.counter) || self.counter >= 0) && (!has(self.seedLength) || (self.seedLength >= 16 && self.seedLength <= 64)))",message="digits must be 6 or 8, period at least 1, counter at least 0 and seedLength between 16 and 64"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || ((!has(self.digits) || self.digits == 6 || self.digits == 8) && (!has(self.period) || self.period >= 1) && (!has(self.counter) || self.counter >= 0) && (!has(self.seedLength) || (self.seedLength >= 16 && self.seedLength <= 64)))",message="digits must be 6 or 8, period at least 1, counter at least 0 and seedLength between 16 and 64",optionalOldSelf=true
+type OTPSpec struct {
+	// Type of the one-time passwords, totp (the default) or hotp.
+	// +optional
+	Type OTPType `json:"type,omitempty"`
+	// Issuer is the provider or service the account belongs to. It is shown by authenticator apps and must not
+	// contain a colon.
+	// +optional
+	// +kubebuilder:validation:MaxLength=256
+	Issuer string `json:"issuer,omitempty"`
+	// AccountName identifies the account, for example an email address. It must not contain a colon.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	AccountName string `json:"accountName"`
+	// Algorithm is the HMAC hash function. Defaults to SHA1, which some authenticator apps assume regardless of
+	// this setting.
+	// +optional
+	Algorithm OTPAlgorithm `json:"algorithm,omitempty"`
+	// Digits of each one-time password, 6 (the default) or 8.
+	// +optional
+	Digits int32 `json:"digits,omitempty"`
+	// Period in seconds for which a time-based one-time password is valid. Defaults to 30.
+	// +optional
+	Period int32 `json:"period,omitempty"`
+	// Counter is the initial counter of counter-based one-time passwords.
+	// +optional
+	Counter int64 `json:"counter,omitempty"`
+	// SeedLength is the number of random bytes of the seed. Defaults to 20, RFC 4226 requires at least 16.
+	// +optional
+	SeedLength int32 `json:"seedLength,omitempty"`
+	// QRCode additionally stores a PNG image of a QR code of the provisioning URI.
+	// +optional
+	QRCode bool `json:"qrCode,omitempty"`
+}
+
+const (
+	// OTPURIKeySuffix is appended to the key of an OTP field to get the key of its provisioning URI.
+	OTPURIKeySuffix string = ".uri"
+	// OTPQRCodeKeySuffix is appended to the key of an OTP field to get the key of its QR code.
+	OTPQRCodeKeySuffix string = ".png"
+)
+
+// ValueFromSource selects a value from another Secret or ConfigMap in the namespace of the claim. Exactly one of
+// SecretKeyRef and ConfigMapKeyRef must be set.
+// +kubebuilder:validation:XValidation:rule="oldSelf == self || has(self.secretKeyRef) != has(self.configMapKeyRef)",message="exactly one of secretKeyRef and configMapKeyRef must be set"
+// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || has(self.secretKeyRef) != has(self.configMapKeyRef)",message="exactly one of secretKeyRef and configMapKeyRef must be set",optionalOldSelf=true
+type ValueFromSource struct {
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// AdoptionPolicy determines what happens if a secret with the name of the claim already exists, but is not controlled
+// by the claim.
+// +kubebuilder:validation:Enum=Refuse;Adopt
+type AdoptionPolicy string
+
+const (
+	// AdoptionPolicyRefuse leaves the secret alone and reports a conflict. This is the default.
+	AdoptionPolicyRefuse AdoptionPolicy = "Refuse"
+	// AdoptionPolicyAdopt makes the claim the controller of the secret, keeping existing values which satisfy the
+	// claim and filling in the rest. Secrets controlled by something else are never adopted.
+	AdoptionPolicyAdopt AdoptionPolicy = "Adopt"
+)
+
+// DeletionPolicy determines what happens to the secret of a claim when the claim is deleted.
+// +kubebuilder:validation:Enum=Delete;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the secret together with the claim. This is the default.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyRetain keeps the secret when the claim is deleted. The secret is no longer owned by the claim and
+	// labeled with LabelOrphaned. Foreground cascading deletion of the claim would let the garbage collector delete
+	// the secret before it is released, the admission webhook rejects it.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+const (
+	// FinalizerRetainSecret is set on claims with DeletionPolicyRetain to release the secret before the claim is
+	// deleted.
+	FinalizerRetainSecret string = GroupName + "/retain-secret"
+	// LabelClaimUID is set on all secrets written by the controller, including replicas, to the UID of the claim they
+	// belong to. Only secrets with this label are cached by the controller.
+	LabelClaimUID string = GroupName + "/claim-uid"
+	// LabelOrphaned is set to "true" on secrets which have been retained after their claim was deleted.
+	LabelOrphaned string = GroupName + "/orphaned"
+	// FinalizerReplicas is set on claims which replicate their secret to other namespaces. Replicas cannot have owner
+	// references to the claim, so they are removed by the controller before the claim is deleted.
+	FinalizerReplicas string = GroupName + "/replicas"
+	// LabelReplicaOf is set on replicated secrets to the UID of the claim they belong to.
+	LabelReplicaOf string = GroupName + "/replica-of"
+	// AnnotationReplicaOf is set on replicated secrets to the namespace and name of the claim they belong to.
+	AnnotationReplicaOf string = GroupName + "/replica-of"
+	// AnnotationAllowReplicationFrom must be set on namespaces into which secrets can be replicated. It contains a
+	// comma-separated list of namespaces whose claims are allowed to replicate into the namespace or "*" to allow all.
+	AnnotationAllowReplicationFrom string = GroupName + "/allow-replication-from"
+)
+
+// Replication configures other namespaces into which the secret of a claim is copied and kept in sync. Target
+// namespaces must consent by listing the namespace of the claim (or "*") in their
+// dolansoft.org/allow-replication-from annotation. Copies are deleted together with the claim, or retained if the
+// deletionPolicy is Retain.
+type Replication struct {
+	// Namespaces into which the secret is replicated
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects additional namespaces into which the secret is replicated
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// SecretTemplate describes the metadata of the secret generated for a claim.
+type SecretTemplate struct {
+	// Name of the secret, defaults to the name of the claim. If it is changed, the previously generated secret is
+	// deleted (or released if the deletionPolicy is Retain).
+	Name string `json:"name,omitempty"`
+	// Type of the secret, for example kubernetes.io/tls or kubernetes.io/basic-auth. Defaults to Opaque. As the type
+	// of a secret cannot be changed, the secret is deleted and recreated with the same data if it changes.
+	Type corev1.SecretType `json:"type,omitempty"`
+	// Labels set on the secret
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations set on the secret
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type SecretClaimSpec struct {
-	TokenFields       []string                   `json:"tokenFields"`
-	FixedFields       map[string]string          `json:"fixedFields"`
+	// These fields are filled with a randomly generated 128 bit token
+	// +optional
+	TokenFields []string `json:"tokenFields"`
+	// These fields are copied as-is into the secret
+	// +optional
+	FixedFields map[string]string `json:"fixedFields"`
+	// These fields are filled with randomly generated tokens in the given encoding
+	// +optional
 	CustomTokenFields map[string]CustomTokenSpec `json:"customTokenFields"`
-	X509Claim         *X509Claim                 `json:"x509,omitempty"`
+	// These fields are copied from a key of another secret or config map in the namespace of the claim. The secret is
+	// updated when the referenced value changes.
+	ValueFromFields map[string]ValueFromSource `json:"valueFromFields,omitempty"`
+	// These fields are filled with a randomly generated seed for one-time passwords. The provisioning URI and QR code
+	// are stored in additional keys, see OTPSpec.
+	OTPFields map[string]OTPSpec `json:"otpFields,omitempty"`
+	X509Claim *X509Claim         `json:"x509,omitempty"`
+	// Determines what happens if a secret with the name of the claim already exists, but was not created by it.
+	// Refuse (the default) leaves the secret alone and reports a conflict. Adopt takes over the secret, keeping
+	// existing values which satisfy the claim and filling in missing fields. Secrets controlled by something else are
+	// never adopted.
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+	// Determines what happens to the secret when the claim is deleted. Delete (the default) deletes it together with
+	// the claim. Retain keeps the secret, removes its owner reference and labels it with dolansoft.org/orphaned=true.
+	// Foreground cascading deletion would delete the secret before it is released and is rejected by the admission
+	// webhook for claims with Retain.
+	DeletionPolicy DeletionPolicy  `json:"deletionPolicy,omitempty"`
+	SecretTemplate *SecretTemplate `json:"secretTemplate,omitempty"`
+	// Immutable writes each generation of the data to a new immutable secret named after the secret and a hash of
+	// its contents, for example db-credentials-5d41402abc. The name of the current secret is published in
+	// status.secretName.
+	Immutable bool `json:"immutable,omitempty"`
+	// RevisionHistoryLimit is the number of previous immutable secrets to keep. Defaults to 2.
+	// +kubebuilder:validation:XValidation:rule="oldSelf == self || self >= 0",message="must be at least 0"
+	// +kubebuilder:validation:XValidation:rule="oldSelf.hasValue() || self >= 0",message="must be at least 0",optionalOldSelf=true
+	RevisionHistoryLimit *int32       `json:"revisionHistoryLimit,omitempty"`
+	Replication          *Replication `json:"replication,omitempty"`
+}
+
+// Condition types used in SecretClaimStatus.Conditions
+const (
+	// ConditionReady is true if the secret exists and matches the claim
+	ConditionReady string = "Ready"
+	// ConditionIssued is true if a valid certificate has been issued for an X.509 claim
+	ConditionIssued string = "Issued"
+	// ConditionRotationDue is true if the certificate of an X.509 claim is in the last third of its
+	// validity period and will be reissued
+	ConditionRotationDue string = "RotationDue"
+	// ConditionReplicated is true if the secret has been replicated to all namespaces selected by the claim
+	ConditionReplicated string = "Replicated"
+)
+
+// CertificateStatus contains details of the currently issued certificate for X.509 claims.
+type CertificateStatus struct {
+	NotAfter metav1.Time `json:"notAfter"`
+	// Hex-encoded serial number
+	SerialNumber string `json:"serialNumber"`
+	// Hex-encoded SHA-256 fingerprint of the DER-encoded certificate
+	Fingerprint string `json:"fingerprint"`
 }
 
 type SecretClaimStatus struct {
+	// Deprecated: never set, use Conditions instead
 	Reason string `json:"reason,omitempty"`
+	// The generation of the claim which was last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Current state of the claim. Ready is true if the secret exists and matches the claim. For X.509 claims Issued
+	// reports if a valid certificate has been issued and RotationDue if the certificate is nearing expiry and will be
+	// reissued. For claims with replication Replicated reports if all selected namespaces received a copy.
+	// +listType=map
+	// +listMapKey=type
+	Conditions  []metav1.Condition `json:"conditions,omitempty"`
+	Certificate *CertificateStatus `json:"certificate,omitempty"`
+	// Time at which the current certificate was issued.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// Name of the secret currently generated for the claim
+	SecretName string `json:"secretName,omitempty"`
+	// Namespaces into which the secret is currently replicated
+	ReplicatedNamespaces []string `json:"replicatedNamespaces,omitempty"`
+	// Entropy of the tokens generated for each custom token field in bits
+	TokenEntropyBits map[string]int32 `json:"tokenEntropyBits,omitempty"`
 }
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=secc
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretName`,priority=1
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.certificate.notAfter`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
+// SecretClaim creates and maintains a secret in its namespace.
 type SecretClaim struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   SecretClaimSpec   `json:"spec"`
+	Spec SecretClaimSpec `json:"spec"`
+	// +optional
 	Status SecretClaimStatus `json:"status"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
 
 type SecretClaimList struct {
 	metav1.TypeMeta `json:",inline"`
@@ -60,3 +430,50 @@ type SecretClaimList struct {
 
 	Items []SecretClaim `json:"items"`
 }
+
+// SecretClaimPolicySpec restricts what claims in the selected namespaces may request. Claims violating any policy
+// which applies to them are not reconciled.
+type SecretClaimPolicySpec struct {
+	// NamespaceSelector selects the namespaces whose claims the policy applies to. The policy applies to all
+	// namespaces if unset.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// MinTokenEntropyBits is the minimum entropy of randomly generated tokens in bits. Token fields have 128 bits.
+	// +kubebuilder:validation:Minimum=0
+	MinTokenEntropyBits *int32 `json:"minTokenEntropyBits,omitempty"`
+	// AllowedEncodings are the encodings custom tokens may use. All encodings are allowed if empty.
+	// +kubebuilder:validation:items:Enum=base64;base64url;hex;upperhex;characterset;raw;uuid
+	AllowedEncodings []string `json:"allowedEncodings,omitempty"`
+	// MaxCertificateLifetime is the longest rotateEvery X.509 claims may request, for example 90d. X.509 claims without
+	// rotateEvery are rejected if it is set.
+	MaxCertificateLifetime string `json:"maxCertificateLifetime,omitempty"`
+	// AllowedCASecretNames are the CA secrets X.509 claims may be signed by. If set, self-signed certificates are not
+	// allowed.
+	AllowedCASecretNames []string `json:"allowedCASecretNames,omitempty"`
+	// AllowFixedFields determines if claims may contain fixedFields. Defaults to true.
+	AllowFixedFields *bool `json:"allowFixedFields,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=seccp
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SecretClaimPolicy limits what SecretClaims in a set of namespaces may request.
+type SecretClaimPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SecretClaimPolicySpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+type SecretClaimPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []SecretClaimPolicy `json:"items"`
+}
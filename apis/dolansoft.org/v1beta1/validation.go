@@ -0,0 +1,325 @@
+package v1beta1
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"maze.io/x/duration"
+)
+
+// MaxTokenLength is the largest number of random bytes a custom token may consist of.
+const MaxTokenLength = 1 * 1024 * 1024
+
+// MaxBase58Length is the largest number of random bytes of base58 tokens. Encoding them takes quadratic time.
+const MaxBase58Length = 256
+
+// MaxPasswordPolicyLength is the largest number of characters of passwordpolicy tokens. Counting the tokens which meet
+// the minimum counts of a policy takes quadratic time.
+const MaxPasswordPolicyLength = 256
+
+// MaxPassphraseWords is the largest number of words a passphrase may consist of.
+const MaxPassphraseWords = 1024
+
+// MinOTPSeedLength and MaxOTPSeedLength bound the number of random bytes of OTP seeds. RFC 4226 requires at least
+// 128 bits.
+const (
+	MinOTPSeedLength = 16
+	MaxOTPSeedLength = 64
+)
+
+// maxOTPNameLength bounds the issuer and account name of OTP fields so that the provisioning URI fits into a QR code.
+const maxOTPNameLength = 256
+
+// maxCommonNameLength is the upper bound for common names from RFC 5280.
+const maxCommonNameLength = 64
+
+// CustomTokenEncodings lists the supported encodings of custom tokens.
+var CustomTokenEncodings = []string{"base64", "base64url", "hex", "upperhex", "characterset", "raw", "uuid", "passwordpolicy", "passphrase",
+	"base32", "crockford", "base58", "z85", "ulid", "uuidv7"}
+
+// ParseRotateEvery parses the validity period of an X.509 claim.
+func ParseRotateEvery(rotateEvery string) (time.Duration, error) {
+	d, err := duration.ParseDuration(rotateEvery)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return time.Duration(d), nil
+}
+
+// LengthIgnored checks if the encoding generates tokens of a fixed size regardless of Length.
+func (s *CustomTokenSpec) LengthIgnored() bool {
+	return s.Encoding == "uuid" || s.Encoding == "uuidv7" || s.Encoding == "ulid"
+}
+
+// Validate checks if tokens can be generated for the spec.
+func (s *CustomTokenSpec) Validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	knownEncoding := false
+	for _, encoding := range CustomTokenEncodings {
+		knownEncoding = knownEncoding || s.Encoding == encoding
+	}
+	if !knownEncoding {
+		errs = append(errs, field.NotSupported(fldPath.Child("encoding"), s.Encoding, CustomTokenEncodings))
+	}
+	if s.Length > MaxTokenLength {
+		errs = append(errs, field.Invalid(fldPath.Child("length"), s.Length, "refusing to issue tokens larger than 1 MiB"))
+	}
+	if !s.LengthIgnored() && s.Length < 1 {
+		errs = append(errs, field.Invalid(fldPath.Child("length"), s.Length, "length needs to be at least 1"))
+	}
+	if s.Encoding == "z85" && s.Length%4 != 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("length"), s.Length, "length needs to be a multiple of 4 for the z85 encoding"))
+	}
+	if s.Encoding == "base58" && s.Length > MaxBase58Length {
+		errs = append(errs, field.Invalid(fldPath.Child("length"), s.Length, fmt.Sprintf("base58 tokens may consist of at most %d bytes", MaxBase58Length)))
+	}
+	if s.Encoding == "characterset" {
+		charset := []rune(s.CharacterSet)
+		if len(charset) == 0 {
+			errs = append(errs, field.Required(fldPath.Child("characterSet"), "for encoding characterset at least one character needs to be in the characterset"))
+		}
+		if len(charset) >= math.MaxUint32 {
+			errs = append(errs, field.TooLong(fldPath.Child("characterSet"), "", math.MaxUint32-1))
+		}
+	}
+	if s.Encoding == "passwordpolicy" {
+		if s.PasswordPolicy == nil {
+			errs = append(errs, field.Required(fldPath.Child("passwordPolicy"), "required for encoding passwordpolicy"))
+		} else {
+			errs = append(errs, s.PasswordPolicy.Validate(fldPath.Child("passwordPolicy"), s.Length)...)
+		}
+		if s.Length > MaxPasswordPolicyLength {
+			errs = append(errs, field.Invalid(fldPath.Child("length"), s.Length, fmt.Sprintf("passwordpolicy tokens may consist of at most %d characters", MaxPasswordPolicyLength)))
+		}
+	} else if s.PasswordPolicy != nil {
+		errs = append(errs, field.Forbidden(fldPath.Child("passwordPolicy"), "only used by encoding passwordpolicy"))
+	}
+	if s.Encoding == "passphrase" {
+		if s.Length > MaxPassphraseWords {
+			errs = append(errs, field.Invalid(fldPath.Child("length"), s.Length, fmt.Sprintf("passphrases may consist of at most %d words", MaxPassphraseWords)))
+		}
+		if s.Passphrase != nil {
+			errs = append(errs, s.Passphrase.Validate(fldPath.Child("passphrase"))...)
+		}
+	} else if s.Passphrase != nil {
+		errs = append(errs, field.Forbidden(fldPath.Child("passphrase"), "only used by encoding passphrase"))
+	}
+	return errs
+}
+
+// Validate checks the options of the passphrase encoding.
+func (p *PassphraseSpec) Validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch p.Capitalization {
+	case "", CapitalizationLower, CapitalizationTitle, CapitalizationUpper, CapitalizationRandom:
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Child("capitalization"), p.Capitalization, []string{
+			string(CapitalizationLower), string(CapitalizationTitle), string(CapitalizationUpper), string(CapitalizationRandom),
+		}))
+	}
+	if p.WordListRef != nil {
+		if p.WordListRef.Name == "" {
+			errs = append(errs, field.Required(fldPath.Child("wordListRef", "name"), ""))
+		}
+		if p.WordListRef.Key == "" {
+			errs = append(errs, field.Required(fldPath.Child("wordListRef", "key"), ""))
+		}
+		if p.WordListRef.Optional != nil && *p.WordListRef.Optional {
+			errs = append(errs, field.Forbidden(fldPath.Child("wordListRef", "optional"), "word lists cannot be optional"))
+		}
+	}
+	return errs
+}
+
+// Validate checks the spec of an OTP field. Unset fields are valid, they are filled in by Default.
+func (s *OTPSpec) Validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch s.Type {
+	case "", OTPTypeTOTP, OTPTypeHOTP:
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Child("type"), s.Type, []string{string(OTPTypeTOTP), string(OTPTypeHOTP)}))
+	}
+	if s.AccountName == "" {
+		errs = append(errs, field.Required(fldPath.Child("accountName"), ""))
+	}
+	for _, name := range []struct {
+		field, value string
+	}{{"issuer", s.Issuer}, {"accountName", s.AccountName}} {
+		if len(name.value) > maxOTPNameLength {
+			errs = append(errs, field.TooLong(fldPath.Child(name.field), name.value, maxOTPNameLength))
+		} else if strings.Contains(name.value, ":") {
+			errs = append(errs, field.Invalid(fldPath.Child(name.field), name.value, "must not contain a colon"))
+		}
+	}
+	switch s.Algorithm {
+	case "", OTPAlgorithmSHA1, OTPAlgorithmSHA256, OTPAlgorithmSHA512:
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Child("algorithm"), s.Algorithm,
+			[]string{string(OTPAlgorithmSHA1), string(OTPAlgorithmSHA256), string(OTPAlgorithmSHA512)}))
+	}
+	if s.Digits != 0 && s.Digits != 6 && s.Digits != 8 {
+		errs = append(errs, field.NotSupported(fldPath.Child("digits"), s.Digits, []string{"6", "8"}))
+	}
+	if s.Type == OTPTypeHOTP {
+		if s.Period != 0 {
+			errs = append(errs, field.Forbidden(fldPath.Child("period"), "is only used for totp"))
+		}
+		if s.Counter < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("counter"), s.Counter, "must not be negative"))
+		}
+	} else {
+		if s.Period < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("period"), s.Period, "must be positive"))
+		}
+		if s.Counter != 0 {
+			errs = append(errs, field.Forbidden(fldPath.Child("counter"), "is only used for hotp"))
+		}
+	}
+	if s.SeedLength != 0 && (s.SeedLength < MinOTPSeedLength || s.SeedLength > MaxOTPSeedLength) {
+		errs = append(errs, field.Invalid(fldPath.Child("seedLength"), s.SeedLength,
+			fmt.Sprintf("must be between %d and %d bytes", MinOTPSeedLength, MaxOTPSeedLength)))
+	}
+	return errs
+}
+
+// Validate checks if certificates can be issued for the claim.
+func (x *X509Claim) Validate(fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if x.CASecretName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(x.CASecretName) {
+			errs = append(errs, field.Invalid(fldPath.Child("caSecretName"), x.CASecretName, msg))
+		}
+	}
+	if len(x.CommonName) > maxCommonNameLength {
+		errs = append(errs, field.TooLong(fldPath.Child("commonName"), x.CommonName, maxCommonNameLength))
+	}
+	if x.RotateEvery != "" {
+		if _, err := ParseRotateEvery(x.RotateEvery); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("rotateEvery"), x.RotateEvery, fmt.Sprintf("cannot parse duration: %v", err)))
+		}
+	}
+	for i, name := range x.ServiceNames {
+		for _, msg := range validation.IsDNS1035Label(name) {
+			errs = append(errs, field.Invalid(fldPath.Child("serviceNames").Index(i), name, msg))
+		}
+	}
+	for i, name := range x.ExtraNames {
+		msgs := validation.IsDNS1123Subdomain(name)
+		if len(msgs) > 0 && len(validation.IsWildcardDNS1123Subdomain(name)) == 0 {
+			msgs = nil
+		}
+		for _, msg := range msgs {
+			errs = append(errs, field.Invalid(fldPath.Child("extraNames").Index(i), name, msg))
+		}
+	}
+	return errs
+}
+
+// Validate checks the spec of the claim. It rejects everything the controller would fail on or silently ignore.
+func (sc *SecretClaim) Validate() field.ErrorList {
+	var errs field.ErrorList
+	spec := &sc.Spec
+	specPath := field.NewPath("spec")
+
+	if spec.X509Claim != nil {
+		errs = append(errs, spec.X509Claim.Validate(specPath.Child("x509"))...)
+		ignored := []struct {
+			name string
+			set  bool
+		}{
+			{"tokenFields", len(spec.TokenFields) > 0},
+			{"fixedFields", len(spec.FixedFields) > 0},
+			{"customTokenFields", len(spec.CustomTokenFields) > 0},
+			{"valueFromFields", len(spec.ValueFromFields) > 0},
+			{"otpFields", len(spec.OTPFields) > 0},
+		}
+		for _, f := range ignored {
+			if f.set {
+				errs = append(errs, field.Forbidden(specPath.Child(f.name), "is ignored for X.509 claims"))
+			}
+		}
+	}
+
+	// Keys must be valid secret keys and may only be declared once
+	seen := make(map[string]bool)
+	checkKey := func(fldPath *field.Path, key string) {
+		for _, msg := range validation.IsConfigMapKey(key) {
+			errs = append(errs, field.Invalid(fldPath, key, msg))
+		}
+		if seen[key] {
+			errs = append(errs, field.Duplicate(fldPath, key))
+		}
+		seen[key] = true
+	}
+	for i, key := range spec.TokenFields {
+		checkKey(specPath.Child("tokenFields").Index(i), key)
+	}
+	for _, key := range sortedKeys(spec.FixedFields) {
+		checkKey(specPath.Child("fixedFields").Key(key), key)
+	}
+	for _, key := range sortedKeys(spec.CustomTokenFields) {
+		tokenSpec := spec.CustomTokenFields[key]
+		fldPath := specPath.Child("customTokenFields").Key(key)
+		checkKey(fldPath, key)
+		errs = append(errs, tokenSpec.Validate(fldPath)...)
+	}
+	for _, key := range sortedKeys(spec.ValueFromFields) {
+		source := spec.ValueFromFields[key]
+		fldPath := specPath.Child("valueFromFields").Key(key)
+		checkKey(fldPath, key)
+		if (source.SecretKeyRef == nil) == (source.ConfigMapKeyRef == nil) {
+			errs = append(errs, field.Invalid(fldPath, key, "exactly one of secretKeyRef and configMapKeyRef must be set"))
+		}
+	}
+
+	for _, key := range sortedKeys(spec.OTPFields) {
+		otpSpec := spec.OTPFields[key]
+		fldPath := specPath.Child("otpFields").Key(key)
+		checkKey(fldPath, key)
+		checkKey(fldPath, key+OTPURIKeySuffix)
+		if otpSpec.QRCode {
+			checkKey(fldPath, key+OTPQRCodeKeySuffix)
+		}
+		errs = append(errs, otpSpec.Validate(fldPath)...)
+	}
+
+	switch spec.AdoptionPolicy {
+	case "", AdoptionPolicyRefuse, AdoptionPolicyAdopt:
+	default:
+		errs = append(errs, field.NotSupported(specPath.Child("adoptionPolicy"), spec.AdoptionPolicy,
+			[]string{string(AdoptionPolicyRefuse), string(AdoptionPolicyAdopt)}))
+	}
+	switch spec.DeletionPolicy {
+	case "", DeletionPolicyDelete, DeletionPolicyRetain:
+	default:
+		errs = append(errs, field.NotSupported(specPath.Child("deletionPolicy"), spec.DeletionPolicy,
+			[]string{string(DeletionPolicyDelete), string(DeletionPolicyRetain)}))
+	}
+	if spec.RevisionHistoryLimit != nil && *spec.RevisionHistoryLimit < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("revisionHistoryLimit"), *spec.RevisionHistoryLimit, "must not be negative"))
+	}
+	if spec.Replication != nil && spec.Replication.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.Replication.NamespaceSelector); err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("replication", "namespaceSelector"), spec.Replication.NamespaceSelector, err.Error()))
+		}
+	}
+	return errs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
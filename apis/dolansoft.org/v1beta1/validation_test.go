@@ -0,0 +1,233 @@
+package v1beta1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func errorFields(errs field.ErrorList) []string {
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, string(err.Type)+" "+err.Field)
+	}
+	return fields
+}
+
+func TestValidateCustomTokenSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec CustomTokenSpec
+		want []string
+	}{
+		{"base64", CustomTokenSpec{Length: 32, Encoding: "base64"}, nil},
+		{"base64url", CustomTokenSpec{Length: 32, Encoding: "base64url"}, nil},
+		{"hex", CustomTokenSpec{Length: 32, Encoding: "hex"}, nil},
+		{"upperhex", CustomTokenSpec{Length: 32, Encoding: "upperhex"}, nil},
+		{"characterset", CustomTokenSpec{Length: 32, Encoding: "characterset", CharacterSet: "äbc"}, nil},
+		{"raw", CustomTokenSpec{Length: 32, Encoding: "raw"}, nil},
+		{"uuid without length", CustomTokenSpec{Encoding: "uuid"}, nil},
+		{"base32", CustomTokenSpec{Length: 20, Encoding: "base32"}, nil},
+		{"crockford", CustomTokenSpec{Length: 16, Encoding: "crockford"}, nil},
+		{"base58", CustomTokenSpec{Length: 32, Encoding: "base58"}, nil},
+		{"z85", CustomTokenSpec{Length: 32, Encoding: "z85"}, nil},
+		{"ulid without length", CustomTokenSpec{Encoding: "ulid"}, nil},
+		{"uuidv7 without length", CustomTokenSpec{Encoding: "uuidv7"}, nil},
+		{"z85 with partial chunk", CustomTokenSpec{Length: 30, Encoding: "z85"}, []string{"FieldValueInvalid spec.length"}},
+		{"Too long base58", CustomTokenSpec{Length: MaxBase58Length + 1, Encoding: "base58"}, []string{"FieldValueInvalid spec.length"}},
+		{"Prefix and suffix", CustomTokenSpec{Length: 32, Encoding: "hex", Prefix: "tok_", Suffix: "_end"}, nil},
+		{"Maximum length", CustomTokenSpec{Length: MaxTokenLength, Encoding: "raw"}, nil},
+		{"Unknown encoding", CustomTokenSpec{Length: 32, Encoding: "base65"}, []string{"FieldValueNotSupported spec.encoding"}},
+		{"Missing encoding", CustomTokenSpec{Length: 32}, []string{"FieldValueNotSupported spec.encoding"}},
+		{"Zero length", CustomTokenSpec{Encoding: "base64"}, []string{"FieldValueInvalid spec.length"}},
+		{"Negative length", CustomTokenSpec{Length: -1, Encoding: "hex"}, []string{"FieldValueInvalid spec.length"}},
+		{"Too long", CustomTokenSpec{Length: MaxTokenLength + 1, Encoding: "raw"}, []string{"FieldValueInvalid spec.length"}},
+		{"Empty character set", CustomTokenSpec{Length: 32, Encoding: "characterset"}, []string{"FieldValueRequired spec.characterSet"}},
+		{"passwordpolicy", CustomTokenSpec{Length: 16, Encoding: "passwordpolicy", PasswordPolicy: &PasswordPolicy{
+			Classes: []CharacterClass{{Name: "lowercase"}, {Name: "digits", MinCount: 2}},
+		}}, nil},
+		{"passphrase", CustomTokenSpec{Length: 6, Encoding: "passphrase"}, nil},
+		{"Too many words", CustomTokenSpec{Length: MaxPassphraseWords + 1, Encoding: "passphrase"}, []string{"FieldValueInvalid spec.length"}},
+		{"Unknown capitalization", CustomTokenSpec{Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{Capitalization: "camel"}},
+			[]string{"FieldValueNotSupported spec.passphrase.capitalization"}},
+		{"Optional word list", CustomTokenSpec{Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{
+			WordListRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "words"}, Key: "list", Optional: &[]bool{true}[0]},
+		}}, []string{"FieldValueForbidden spec.passphrase.wordListRef.optional"}},
+		{"Ignored passphrase", CustomTokenSpec{Length: 6, Encoding: "hex", Passphrase: &PassphraseSpec{}}, []string{"FieldValueForbidden spec.passphrase"}},
+		{"Too long passwordpolicy", CustomTokenSpec{Length: MaxPasswordPolicyLength + 1, Encoding: "passwordpolicy", PasswordPolicy: &PasswordPolicy{
+			Classes: []CharacterClass{{Name: "lowercase"}},
+		}}, []string{"FieldValueInvalid spec.length"}},
+		{"Missing password policy", CustomTokenSpec{Length: 16, Encoding: "passwordpolicy"}, []string{"FieldValueRequired spec.passwordPolicy"}},
+		{"Ignored password policy", CustomTokenSpec{Length: 16, Encoding: "hex", PasswordPolicy: &PasswordPolicy{
+			Classes: []CharacterClass{{Name: "digits"}},
+		}}, []string{"FieldValueForbidden spec.passwordPolicy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorFields(tt.spec.Validate(field.NewPath("spec"))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateX509Claim(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim X509Claim
+		want  []string
+	}{
+		{"Empty", X509Claim{}, nil},
+		{"caSecretName", X509Claim{CASecretName: "my-ca"}, nil},
+		{"Invalid caSecretName", X509Claim{CASecretName: "My_CA"}, []string{"FieldValueInvalid x509.caSecretName"}},
+		{"isCA", X509Claim{IsCA: true}, nil},
+		{"Intermediate CA", X509Claim{IsCA: true, CASecretName: "root-ca"}, nil},
+		{"commonName", X509Claim{CommonName: "My Service"}, nil},
+		{"Too long commonName", X509Claim{CommonName: strings.Repeat("a", 65)}, []string{"FieldValueTooLong x509.commonName"}},
+		{"rotateEvery", X509Claim{RotateEvery: "90d"}, nil},
+		{"Unparseable rotateEvery", X509Claim{RotateEvery: "soon"}, []string{"FieldValueInvalid x509.rotateEvery"}},
+		{"Negative rotateEvery", X509Claim{RotateEvery: "-1h"}, []string{"FieldValueInvalid x509.rotateEvery"}},
+		{"serviceNames", X509Claim{ServiceNames: []string{"hello", "world"}}, nil},
+		{"Invalid serviceNames", X509Claim{ServiceNames: []string{"hello", "hello.world"}}, []string{"FieldValueInvalid x509.serviceNames[1]"}},
+		{"extraNames", X509Claim{ExtraNames: []string{"example.com", "*.example.com"}}, nil},
+		{"Invalid extraNames", X509Claim{ExtraNames: []string{"", "exa mple.com"}}, []string{"FieldValueInvalid x509.extraNames[0]", "FieldValueInvalid x509.extraNames[1]"}},
+		{"legacySEC1PrivateKey", X509Claim{LegacySEC1PrivateKey: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorFields(tt.claim.Validate(field.NewPath("x509"))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSecretClaim(t *testing.T) {
+	negative := int32(-1)
+	tests := []struct {
+		name string
+		spec SecretClaimSpec
+		want []string
+	}{
+		{"Empty", SecretClaimSpec{}, nil},
+		{"All field kinds", SecretClaimSpec{
+			TokenFields:       []string{"token"},
+			FixedFields:       map[string]string{"user": "admin"},
+			CustomTokenFields: map[string]CustomTokenSpec{"password": {Length: 16, Encoding: "base64"}},
+			ValueFromFields: map[string]ValueFromSource{
+				"host": {ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "host"}},
+			},
+		}, nil},
+		{"Invalid key", SecretClaimSpec{TokenFields: []string{"my/token"}}, []string{"FieldValueInvalid spec.tokenFields[0]"}},
+		{"Duplicate key", SecretClaimSpec{
+			TokenFields: []string{"token"},
+			FixedFields: map[string]string{"token": "fixed"},
+		}, []string{"FieldValueDuplicate spec.fixedFields[token]"}},
+		{"Invalid custom token", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{"password": {Encoding: "hex"}},
+		}, []string{"FieldValueInvalid spec.customTokenFields[password].length"}},
+		{"valueFrom without source", SecretClaimSpec{
+			ValueFromFields: map[string]ValueFromSource{"host": {}},
+		}, []string{"FieldValueInvalid spec.valueFromFields[host]"}},
+		{"valueFrom with both sources", SecretClaimSpec{
+			ValueFromFields: map[string]ValueFromSource{"host": {
+				SecretKeyRef:    &corev1.SecretKeySelector{Key: "host"},
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "host"},
+			}},
+		}, []string{"FieldValueInvalid spec.valueFromFields[host]"}},
+		{"X.509 with other fields", SecretClaimSpec{
+			X509Claim:   &X509Claim{RotateEvery: "soon"},
+			TokenFields: []string{"token"},
+			FixedFields: map[string]string{"user": "admin"},
+		}, []string{"FieldValueInvalid spec.x509.rotateEvery", "FieldValueForbidden spec.tokenFields", "FieldValueForbidden spec.fixedFields"}},
+		{"OTP fields", SecretClaimSpec{
+			OTPFields: map[string]OTPSpec{"totp": {Issuer: "Example", AccountName: "bot", QRCode: true}},
+		}, nil},
+		{"OTP key taken", SecretClaimSpec{
+			FixedFields: map[string]string{"totp.uri": "otpauth://"},
+			OTPFields:   map[string]OTPSpec{"totp": {AccountName: "bot"}},
+		}, []string{"FieldValueDuplicate spec.otpFields[totp]"}},
+		{"Invalid OTP field", SecretClaimSpec{
+			OTPFields: map[string]OTPSpec{"totp": {Issuer: "a:b", Digits: 7, Counter: 1, SeedLength: 8}},
+		}, []string{
+			"FieldValueRequired spec.otpFields[totp].accountName",
+			"FieldValueInvalid spec.otpFields[totp].issuer",
+			"FieldValueNotSupported spec.otpFields[totp].digits",
+			"FieldValueForbidden spec.otpFields[totp].counter",
+			"FieldValueInvalid spec.otpFields[totp].seedLength",
+		}},
+		{"HOTP with period", SecretClaimSpec{
+			OTPFields: map[string]OTPSpec{"hotp": {Type: OTPTypeHOTP, AccountName: "bot", Period: 30}},
+		}, []string{"FieldValueForbidden spec.otpFields[hotp].period"}},
+		{"Policies", SecretClaimSpec{AdoptionPolicy: AdoptionPolicyAdopt, DeletionPolicy: DeletionPolicyRetain}, nil},
+		{"Unknown policies", SecretClaimSpec{AdoptionPolicy: "Steal", DeletionPolicy: "Shred"},
+			[]string{"FieldValueNotSupported spec.adoptionPolicy", "FieldValueNotSupported spec.deletionPolicy"}},
+		{"Negative revisionHistoryLimit", SecretClaimSpec{RevisionHistoryLimit: &negative}, []string{"FieldValueInvalid spec.revisionHistoryLimit"}},
+		{"Invalid namespaceSelector", SecretClaimSpec{Replication: &Replication{NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Maybe"}},
+		}}}, []string{"FieldValueInvalid spec.replication.namespaceSelector"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SecretClaim{Spec: tt.spec}
+			if got := errorFields(sc.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	limit := int32(5)
+	dash, space := "-", " "
+	tests := []struct {
+		name string
+		spec SecretClaimSpec
+		want SecretClaimSpec
+	}{
+		{"Empty", SecretClaimSpec{}, SecretClaimSpec{
+			AdoptionPolicy:       AdoptionPolicyRefuse,
+			DeletionPolicy:       DeletionPolicyDelete,
+			RevisionHistoryLimit: &[]int32{DefaultRevisionHistoryLimit}[0],
+		}},
+		{"Already set", SecretClaimSpec{
+			AdoptionPolicy:       AdoptionPolicyAdopt,
+			DeletionPolicy:       DeletionPolicyRetain,
+			RevisionHistoryLimit: &limit,
+		}, SecretClaimSpec{
+			AdoptionPolicy:       AdoptionPolicyAdopt,
+			DeletionPolicy:       DeletionPolicyRetain,
+			RevisionHistoryLimit: &limit,
+		}},
+		{"Passphrase", SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"phrase": {Length: 6, Encoding: "passphrase"},
+				"spaced": {Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{Separator: &space}},
+			},
+		}, SecretClaimSpec{
+			CustomTokenFields: map[string]CustomTokenSpec{
+				"phrase": {Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{Separator: &dash, Capitalization: CapitalizationLower}},
+				"spaced": {Length: 6, Encoding: "passphrase", Passphrase: &PassphraseSpec{Separator: &space, Capitalization: CapitalizationLower}},
+			},
+			AdoptionPolicy:       AdoptionPolicyRefuse,
+			DeletionPolicy:       DeletionPolicyDelete,
+			RevisionHistoryLimit: &[]int32{DefaultRevisionHistoryLimit}[0],
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &SecretClaim{Spec: tt.spec}
+			sc.Default()
+			if !reflect.DeepEqual(sc.Spec, tt.want) {
+				t.Errorf("Default() = %+v, want %+v", sc.Spec, tt.want)
+			}
+			if errs := sc.Validate(); len(errs) > 0 {
+				t.Errorf("defaulted claim is invalid: %v", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func hasFinalizer(sc *v1beta1.SecretClaim, finalizer string) bool {
+	for _, f := range sc.Finalizers {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(finalizers []string, finalizer string) []string {
+	var out []string
+	for _, f := range finalizers {
+		if f != finalizer {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func setFinalizer(sc *v1beta1.SecretClaim, finalizer string, present bool) {
+	if present == hasFinalizer(sc, finalizer) {
+		return
+	}
+	if present {
+		sc.Finalizers = append(sc.Finalizers, finalizer)
+	} else {
+		sc.Finalizers = removeFinalizer(sc.Finalizers, finalizer)
+	}
+}
+
+// syncFinalizers adds or removes the finalizers of a claim depending on its deletion policy and replication. It
+// returns the updated claim.
+func (c *controller) syncFinalizers(ctx context.Context, sc *v1beta1.SecretClaim) (*v1beta1.SecretClaim, error) {
+	newSC := sc.DeepCopy()
+	setFinalizer(newSC, v1beta1.FinalizerRetainSecret, sc.Spec.DeletionPolicy == v1beta1.DeletionPolicyRetain)
+	// Keep the finalizer until all replicas have been removed after replication has been turned off
+	setFinalizer(newSC, v1beta1.FinalizerReplicas, sc.Spec.Replication != nil || len(sc.Status.ReplicatedNamespaces) > 0)
+	if equality.Semantic.DeepEqual(newSC.Finalizers, sc.Finalizers) {
+		return sc, nil
+	}
+	updatedSC, err := c.dsclient.DolansoftV1beta1().SecretClaims(sc.Namespace).Update(ctx, newSC, metav1.UpdateOptions{FieldManager: fieldManager})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update finalizers: %w", err)
+	}
+	return updatedSC, nil
+}
+
+// finalizeSC runs when a claim is being deleted. For claims with the retention finalizer it releases the secret so
+// that it is not garbage collected. Replicas are deleted, or released as well if the secret is retained. Afterwards
+// the finalizers are removed.
+func (c *controller) finalizeSC(ctx context.Context, sc *v1beta1.SecretClaim) error {
+	retain := hasFinalizer(sc, v1beta1.FinalizerRetainSecret)
+	replicas := hasFinalizer(sc, v1beta1.FinalizerReplicas)
+	if !retain && !replicas {
+		return nil // K8s GC automatically cleans up after us
+	}
+	if replicas {
+		if err := c.removeReplicas(ctx, sc, retain); err != nil {
+			return err
+		}
+	}
+	if retain {
+		secret, err := c.getSecret(ctx, sc.Namespace, currentSecretName(sc))
+		if err != nil {
+			return err
+		}
+		foreground := hasFinalizer(sc, metav1.FinalizerDeleteDependents)
+		switch {
+		case secret != nil && secret.DeletionTimestamp == nil && metav1.IsControlledBy(secret, sc):
+			if err := c.releaseSecret(ctx, sc, secret); err != nil {
+				return err
+			}
+		case foreground:
+			// The garbage collector was faster. The validating webhook rejects foreground deletion of such claims,
+			// but it may not be deployed or have been unavailable.
+			c.recorder.Eventf(sc, nil, corev1.EventTypeWarning, "SecretNotRetained", "Release",
+				"Secret \"%s\" was deleted by foreground cascading deletion before it could be released", currentSecretName(sc))
+		}
+	}
+	newSC := sc.DeepCopy()
+	newSC.Finalizers = removeFinalizer(removeFinalizer(sc.Finalizers, v1beta1.FinalizerRetainSecret), v1beta1.FinalizerReplicas)
+	if _, err := c.dsclient.DolansoftV1beta1().SecretClaims(sc.Namespace).Update(ctx, newSC, metav1.UpdateOptions{FieldManager: fieldManager}); err != nil {
+		return fmt.Errorf("failed to remove finalizers: %w", err)
+	}
+	return nil
+}
+
+// releaseSecret removes the OwnerReference of the claim from its secret, or the replica labels from a replica, and
+// labels it as orphaned.
+func (c *controller) releaseSecret(ctx context.Context, sc *v1beta1.SecretClaim, secret *corev1.Secret) error {
+	metadata := map[string]interface{}{
+		"labels":      map[string]interface{}{v1beta1.LabelOrphaned: "true", v1beta1.LabelClaimUID: nil, v1beta1.LabelReplicaOf: nil},
+		"annotations": map[string]interface{}{v1beta1.AnnotationReplicaOf: nil},
+	}
+	// Replicas in other namespaces have no owner reference, deleting a missing one would add an invalid reference
+	for _, ref := range secret.OwnerReferences {
+		if ref.UID == sc.UID {
+			metadata["ownerReferences"] = []map[string]interface{}{{"$patch": "delete", "uid": sc.UID}}
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err != nil {
+		panic(err)
+	}
+	releasedSecret, err := c.kclient.CoreV1().Secrets(secret.Namespace).Patch(ctx, secret.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		return fmt.Errorf("failed to release secret: %w", err)
+	}
+	c.recorder.Eventf(sc, releasedSecret, corev1.EventTypeNormal, "SecretRetained", "Release", "Retained secret \"%s/%s\" as the claim is deleted", secret.Namespace, secret.Name)
+	return nil
+}
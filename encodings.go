@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	base58Alphabet    = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	z85Alphabet       = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+)
+
+var crockfordEncoding = base32.NewEncoding(crockfordAlphabet).WithPadding(base32.NoPadding)
+
+// decodeCanonical decodes s and checks that it is the canonical encoding of exactly length bytes, so that tokens which
+// only differ in padding or unused trailing bits are not accepted.
+func decodeCanonical(encoding *base32.Encoding, s string, length int) bool {
+	out, err := encoding.DecodeString(s)
+	return err == nil && len(out) == length && encoding.EncodeToString(out) == s
+}
+
+// encodeBase58 encodes data with the Bitcoin alphabet. Leading zero bytes are encoded as leading 1s.
+func encodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	// Base 58 digits of the number, least significant first
+	var digits []byte
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	var strb strings.Builder
+	strb.WriteString(strings.Repeat("1", zeros))
+	for i := len(digits) - 1; i >= 0; i-- {
+		strb.WriteByte(base58Alphabet[digits[i]])
+	}
+	return strb.String()
+}
+
+// decodeBase58 is the inverse of encodeBase58.
+func decodeBase58(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// Bytes of the number, least significant first
+	var out []byte
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		for j := range out {
+			carry += int(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out = append(out, byte(carry))
+			carry >>= 8
+		}
+	}
+	decoded := make([]byte, zeros, zeros+len(out))
+	for i := len(out) - 1; i >= 0; i-- {
+		decoded = append(decoded, out[i])
+	}
+	return decoded, nil
+}
+
+// encodeZ85 encodes data, which must have a multiple of 4 bytes, as described in ZeroMQ RFC 32.
+func encodeZ85(data []byte) string {
+	var strb strings.Builder
+	for i := 0; i+4 <= len(data); i += 4 {
+		value := binary.BigEndian.Uint32(data[i:])
+		var chunk [5]byte
+		for j := 4; j >= 0; j-- {
+			chunk[j] = z85Alphabet[value%85]
+			value /= 85
+		}
+		strb.Write(chunk[:])
+	}
+	return strb.String()
+}
+
+// decodeZ85 is the inverse of encodeZ85.
+func decodeZ85(s string) ([]byte, error) {
+	if len(s)%5 != 0 {
+		return nil, fmt.Errorf("z85 strings need to have a multiple of 5 characters")
+	}
+	out := make([]byte, 0, len(s)/5*4)
+	for i := 0; i < len(s); i += 5 {
+		var value uint64
+		for j := 0; j < 5; j++ {
+			digit := strings.IndexByte(z85Alphabet, s[i+j])
+			if digit < 0 {
+				return nil, fmt.Errorf("invalid z85 character %q", s[i+j])
+			}
+			value = value*85 + uint64(digit)
+		}
+		if value > 0xffffffff {
+			return nil, fmt.Errorf("z85 chunk %q out of range", s[i:i+5])
+		}
+		out = binary.BigEndian.AppendUint32(out, uint32(value))
+	}
+	return out, nil
+}
+
+// timeOrderedID returns 16 bytes starting with the 48-bit Unix timestamp in milliseconds, followed by random bytes.
+func timeOrderedID(now time.Time) ([16]byte, error) {
+	var id [16]byte
+	ms := uint64(now.UnixMilli())
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	if _, err := io.ReadFull(rand.Reader, id[6:]); err != nil {
+		return id, fmt.Errorf("unable to acquire randomness: %w", err)
+	}
+	return id, nil
+}
+
+// newULID generates a ULID (https://github.com/ulid/spec) for the given time.
+func newULID(now time.Time) (string, error) {
+	id, err := timeOrderedID(now)
+	if err != nil {
+		return "", err
+	}
+	// The 128 bits are encoded as 26 characters of 5 bits each, with two leading zero bits
+	bit := func(i int) byte {
+		if i < 0 {
+			return 0
+		}
+		return id[i/8] >> (7 - i%8) & 1
+	}
+	var out [26]byte
+	for i := range out {
+		var value byte
+		for j := 0; j < 5; j++ {
+			value = value<<1 | bit(5*i+j-2)
+		}
+		out[i] = crockfordAlphabet[value]
+	}
+	return string(out[:]), nil
+}
+
+// ulidValid checks if s is a ULID in canonical (upper case) form.
+func ulidValid(s string) bool {
+	if len(s) != 26 || s[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(crockfordAlphabet, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// newUUIDv7 generates a version 7 UUID as specified in RFC 9562 for the given time.
+func newUUIDv7(now time.Time) (string, error) {
+	id, err := timeOrderedID(now)
+	if err != nil {
+		return "", err
+	}
+	id[6] = id[6]&0x0f | 0x70 // Version 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+	return uuid.UUID(id).String(), nil
+}
+
+// uuidv7Valid checks if s is a version 7 UUID in canonical (lower case) form.
+func uuidv7Valid(s string) bool {
+	id, err := uuid.Parse(s)
+	return err == nil && id.Version() == 7 && id.Variant() == uuid.RFC4122 && id.String() == s
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+)
+
+func TestBase58(t *testing.T) {
+	tests := []struct {
+		data    []byte
+		encoded string
+	}{
+		{[]byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{[]byte{0, 0, 1}, "112"},
+		{[]byte{0}, "1"},
+		{[]byte{255, 255}, "LUv"},
+	}
+	for _, tt := range tests {
+		if got := encodeBase58(tt.data); got != tt.encoded {
+			t.Errorf("encodeBase58(%x) = %q, want %q", tt.data, got, tt.encoded)
+		}
+		if got, err := decodeBase58(tt.encoded); err != nil || !bytes.Equal(got, tt.data) {
+			t.Errorf("decodeBase58(%q) = %x, %v, want %x", tt.encoded, got, err, tt.data)
+		}
+	}
+	if _, err := decodeBase58("0OIl"); err == nil {
+		t.Errorf("expected characters outside the alphabet to be rejected")
+	}
+}
+
+func TestZ85(t *testing.T) {
+	// Test vector from ZeroMQ RFC 32
+	data := []byte{0x86, 0x4F, 0xD2, 0x6F, 0xB5, 0x59, 0xF7, 0x5B}
+	if got := encodeZ85(data); got != "HelloWorld" {
+		t.Errorf("encodeZ85() = %q, want HelloWorld", got)
+	}
+	if got, err := decodeZ85("HelloWorld"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("decodeZ85() = %x, %v, want %x", got, err, data)
+	}
+	for _, invalid := range []string{"Hell", "Hello\"orld", "#####"} {
+		if got, err := decodeZ85(invalid); err == nil {
+			t.Errorf("decodeZ85(%q) = %x, expected an error", invalid, got)
+		}
+	}
+}
+
+func TestTimeOrderedIDs(t *testing.T) {
+	now := time.UnixMilli(1469918176385)
+	id, err := newULID(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The timestamp from the ULID spec encodes as 01ARYZ6S41
+	if !strings.HasPrefix(id, "01ARYZ6S41") || !ulidValid(id) {
+		t.Errorf("unexpected ULID %q", id)
+	}
+	later, err := newULID(now.Add(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if later <= id {
+		t.Errorf("expected ULIDs to be ordered by time, got %q after %q", later, id)
+	}
+	for _, invalid := range []string{"81ARYZ6S41TSV4RRFFQ69G5FAV", "01aryz6s41tsv4rrffq69g5fav", "01ARYZ6S41TSV4RRFFQ69G5FA", "01ARYZ6S41TSV4RRFFQ69G5FAU"} {
+		if ulidValid(invalid) {
+			t.Errorf("expected ULID %q to be invalid", invalid)
+		}
+	}
+
+	u, err := newUUIDv7(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(u, "01563df3-6481-7") || !uuidv7Valid(u) {
+		t.Errorf("unexpected UUIDv7 %q", u)
+	}
+	for _, invalid := range []string{strings.ToUpper(u), "{" + u + "}", "9b2f3b28-8a4e-4f4e-9d3c-2b1a0f6e5d4c"} {
+		if uuidv7Valid(invalid) {
+			t.Errorf("expected UUIDv7 %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestCustomTokenEncodings(t *testing.T) {
+	tests := []struct {
+		spec    v1beta1.CustomTokenSpec
+		invalid []string
+	}{
+		{v1beta1.CustomTokenSpec{Length: 20, Encoding: "base32"}, []string{"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ====", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq"}},
+		{v1beta1.CustomTokenSpec{Length: 3, Encoding: "base32"}, []string{"AAAAB===", "AAAAA"}},
+		{v1beta1.CustomTokenSpec{Length: 16, Encoding: "crockford"}, []string{"0000000000000000000000000Z", "0000000000000000000000000"}},
+		{v1beta1.CustomTokenSpec{Length: 32, Encoding: "base58"}, []string{"0000", "1"}},
+		{v1beta1.CustomTokenSpec{Length: 32, Encoding: "z85"}, []string{"HelloWorld"}},
+		{v1beta1.CustomTokenSpec{Encoding: "ulid", Prefix: "id_"}, []string{"id_01aryz6s41tsv4rrffq69g5fav"}},
+		{v1beta1.CustomTokenSpec{Encoding: "uuidv7"}, []string{"9b2f3b28-8a4e-4f4e-9d3c-2b1a0f6e5d4c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec.Encoding, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				token, err := makeCustomToken(&tt.spec, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !customTokenValid(&tt.spec, nil, token) {
+					t.Fatalf("generated token %q is not valid", token)
+				}
+			}
+			for _, token := range tt.invalid {
+				if customTokenValid(&tt.spec, nil, token) {
+					t.Errorf("expected %q to be invalid", token)
+				}
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ package versioned
 
 import (
 	"fmt"
+	"net/http"
 
+	dolansoftv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1"
 	dolansoftv1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -14,13 +16,14 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	DolansoftV1beta1() dolansoftv1beta1.DolansoftV1beta1Interface
+	DolansoftV1() dolansoftv1.DolansoftV1Interface
 }
 
-// Clientset contains the clients for groups. Each group has exactly one
-// version included in a Clientset.
+// Clientset contains the clients for groups.
 type Clientset struct {
 	*discovery.DiscoveryClient
 	dolansoftV1beta1 *dolansoftv1beta1.DolansoftV1beta1Client
+	dolansoftV1      *dolansoftv1.DolansoftV1Client
 }
 
 // DolansoftV1beta1 retrieves the DolansoftV1beta1Client
@@ -28,6 +31,11 @@ func (c *Clientset) DolansoftV1beta1() dolansoftv1beta1.DolansoftV1beta1Interfac
 	return c.dolansoftV1beta1
 }
 
+// DolansoftV1 retrieves the DolansoftV1Client
+func (c *Clientset) DolansoftV1() dolansoftv1.DolansoftV1Interface {
+	return c.dolansoftV1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -39,22 +47,49 @@ func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 // NewForConfig creates a new Clientset for the given config.
 // If config's RateLimiter is not set and QPS and Burst are acceptable,
 // NewForConfig will generate a rate-limiter in configShallowCopy.
+// NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
+// where httpClient was generated with rest.HTTPClientFor(c).
 func NewForConfig(c *rest.Config) (*Clientset, error) {
 	configShallowCopy := *c
+
+	if configShallowCopy.UserAgent == "" {
+		configShallowCopy.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	// share the transport between all clients
+	httpClient, err := rest.HTTPClientFor(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewForConfigAndClient(&configShallowCopy, httpClient)
+}
+
+// NewForConfigAndClient creates a new Clientset for the given config and http client.
+// Note the http client provided takes precedence over the configured transport values.
+// If config's RateLimiter is not set and QPS and Burst are acceptable,
+// NewForConfigAndClient will generate a rate-limiter in configShallowCopy.
+func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset, error) {
+	configShallowCopy := *c
 	if configShallowCopy.RateLimiter == nil && configShallowCopy.QPS > 0 {
 		if configShallowCopy.Burst <= 0 {
 			return nil, fmt.Errorf("burst is required to be greater than 0 when RateLimiter is not set and QPS is set to greater than 0")
 		}
 		configShallowCopy.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(configShallowCopy.QPS, configShallowCopy.Burst)
 	}
+
 	var cs Clientset
 	var err error
-	cs.dolansoftV1beta1, err = dolansoftv1beta1.NewForConfig(&configShallowCopy)
+	cs.dolansoftV1beta1, err = dolansoftv1beta1.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	cs.dolansoftV1, err = dolansoftv1.NewForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
-	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
+	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
 		return nil, err
 	}
@@ -64,17 +99,18 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 // NewForConfigOrDie creates a new Clientset for the given config and
 // panics if there is an error in the config.
 func NewForConfigOrDie(c *rest.Config) *Clientset {
-	var cs Clientset
-	cs.dolansoftV1beta1 = dolansoftv1beta1.NewForConfigOrDie(c)
-
-	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
-	return &cs
+	cs, err := NewForConfig(c)
+	if err != nil {
+		panic(err)
+	}
+	return cs
 }
 
 // New creates a new Clientset for the given RESTClient.
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.dolansoftV1beta1 = dolansoftv1beta1.New(c)
+	cs.dolansoftV1 = dolansoftv1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -4,6 +4,8 @@ package fake
 
 import (
 	clientset "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned"
+	dolansoftv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1"
+	fakedolansoftv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1/fake"
 	dolansoftv1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1beta1"
 	fakedolansoftv1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -67,3 +69,8 @@ var (
 func (c *Clientset) DolansoftV1beta1() dolansoftv1beta1.DolansoftV1beta1Interface {
 	return &fakedolansoftv1beta1.FakeDolansoftV1beta1{Fake: &c.Fake}
 }
+
+// DolansoftV1 retrieves the DolansoftV1Client
+func (c *Clientset) DolansoftV1() dolansoftv1.DolansoftV1Interface {
+	return &fakedolansoftv1.FakeDolansoftV1{Fake: &c.Fake}
+}
@@ -0,0 +1,24 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/typed/dolansoft.org/v1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeDolansoftV1 struct {
+	*testing.Fake
+}
+
+func (c *FakeDolansoftV1) SecretClaims(namespace string) v1.SecretClaimInterface {
+	return &FakeSecretClaims{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeDolansoftV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
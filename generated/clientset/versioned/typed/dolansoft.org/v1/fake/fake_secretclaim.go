@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	dolansoftorgv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSecretClaims implements SecretClaimInterface
+type FakeSecretClaims struct {
+	Fake *FakeDolansoftV1
+	ns   string
+}
+
+var secretclaimsResource = schema.GroupVersionResource{Group: "dolansoft.org", Version: "v1", Resource: "secretclaims"}
+
+var secretclaimsKind = schema.GroupVersionKind{Group: "dolansoft.org", Version: "v1", Kind: "SecretClaim"}
+
+// Get takes name of the secretClaim, and returns the corresponding secretClaim object, and an error if there is any.
+func (c *FakeSecretClaims) Get(ctx context.Context, name string, options v1.GetOptions) (result *dolansoftorgv1.SecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(secretclaimsResource, c.ns, name), &dolansoftorgv1.SecretClaim{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dolansoftorgv1.SecretClaim), err
+}
+
+// List takes label and field selectors, and returns the list of SecretClaims that match those selectors.
+func (c *FakeSecretClaims) List(ctx context.Context, opts v1.ListOptions) (result *dolansoftorgv1.SecretClaimList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(secretclaimsResource, secretclaimsKind, c.ns, opts), &dolansoftorgv1.SecretClaimList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &dolansoftorgv1.SecretClaimList{ListMeta: obj.(*dolansoftorgv1.SecretClaimList).ListMeta}
+	for _, item := range obj.(*dolansoftorgv1.SecretClaimList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested secretClaims.
+func (c *FakeSecretClaims) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(secretclaimsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a secretClaim and creates it.  Returns the server's representation of the secretClaim, and an error, if there is any.
+func (c *FakeSecretClaims) Create(ctx context.Context, secretClaim *dolansoftorgv1.SecretClaim, opts v1.CreateOptions) (result *dolansoftorgv1.SecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(secretclaimsResource, c.ns, secretClaim), &dolansoftorgv1.SecretClaim{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dolansoftorgv1.SecretClaim), err
+}
+
+// Update takes the representation of a secretClaim and updates it. Returns the server's representation of the secretClaim, and an error, if there is any.
+func (c *FakeSecretClaims) Update(ctx context.Context, secretClaim *dolansoftorgv1.SecretClaim, opts v1.UpdateOptions) (result *dolansoftorgv1.SecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(secretclaimsResource, c.ns, secretClaim), &dolansoftorgv1.SecretClaim{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dolansoftorgv1.SecretClaim), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeSecretClaims) UpdateStatus(ctx context.Context, secretClaim *dolansoftorgv1.SecretClaim, opts v1.UpdateOptions) (*dolansoftorgv1.SecretClaim, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(secretclaimsResource, "status", c.ns, secretClaim), &dolansoftorgv1.SecretClaim{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dolansoftorgv1.SecretClaim), err
+}
+
+// Delete takes name of the secretClaim and deletes it. Returns an error if one occurs.
+func (c *FakeSecretClaims) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(secretclaimsResource, c.ns, name, opts), &dolansoftorgv1.SecretClaim{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSecretClaims) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(secretclaimsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &dolansoftorgv1.SecretClaimList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched secretClaim.
+func (c *FakeSecretClaims) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *dolansoftorgv1.SecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(secretclaimsResource, c.ns, name, pt, data, subresources...), &dolansoftorgv1.SecretClaim{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dolansoftorgv1.SecretClaim), err
+}
@@ -3,6 +3,8 @@
 package v1beta1
 
 import (
+	"net/http"
+
 	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
 	"git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/scheme"
 	rest "k8s.io/client-go/rest"
@@ -11,6 +13,7 @@ import (
 type DolansoftV1beta1Interface interface {
 	RESTClient() rest.Interface
 	SecretClaimsGetter
+	SecretClaimPoliciesGetter
 }
 
 // DolansoftV1beta1Client is used to interact with features provided by the dolansoft.org group.
@@ -22,13 +25,33 @@ func (c *DolansoftV1beta1Client) SecretClaims(namespace string) SecretClaimInter
 	return newSecretClaims(c, namespace)
 }
 
+func (c *DolansoftV1beta1Client) SecretClaimPolicies() SecretClaimPolicyInterface {
+	return newSecretClaimPolicies(c)
+}
+
 // NewForConfig creates a new DolansoftV1beta1Client for the given config.
+// NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
+// where httpClient was generated with rest.HTTPClientFor(c).
 func NewForConfig(c *rest.Config) (*DolansoftV1beta1Client, error) {
 	config := *c
 	if err := setConfigDefaults(&config); err != nil {
 		return nil, err
 	}
-	client, err := rest.RESTClientFor(&config)
+	httpClient, err := rest.HTTPClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return NewForConfigAndClient(&config, httpClient)
+}
+
+// NewForConfigAndClient creates a new DolansoftV1beta1Client for the given config and http client.
+// Note the http client provided takes precedence over the configured transport values.
+func NewForConfigAndClient(c *rest.Config, h *http.Client) (*DolansoftV1beta1Client, error) {
+	config := *c
+	if err := setConfigDefaults(&config); err != nil {
+		return nil, err
+	}
+	client, err := rest.RESTClientForConfigAndClient(&config, h)
 	if err != nil {
 		return nil, err
 	}
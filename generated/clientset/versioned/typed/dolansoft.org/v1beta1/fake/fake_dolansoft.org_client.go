@@ -16,6 +16,10 @@ func (c *FakeDolansoftV1beta1) SecretClaims(namespace string) v1beta1.SecretClai
 	return &FakeSecretClaims{c, namespace}
 }
 
+func (c *FakeDolansoftV1beta1) SecretClaimPolicies() v1beta1.SecretClaimPolicyInterface {
+	return &FakeSecretClaimPolicies{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeDolansoftV1beta1) RESTClient() rest.Interface {
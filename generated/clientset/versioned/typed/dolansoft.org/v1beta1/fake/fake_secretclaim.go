@@ -101,7 +101,7 @@ func (c *FakeSecretClaims) UpdateStatus(ctx context.Context, secretClaim *v1beta
 // Delete takes name of the secretClaim and deletes it. Returns an error if one occurs.
 func (c *FakeSecretClaims) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewDeleteAction(secretclaimsResource, c.ns, name), &v1beta1.SecretClaim{})
+		Invokes(testing.NewDeleteActionWithOptions(secretclaimsResource, c.ns, name, opts), &v1beta1.SecretClaim{})
 
 	return err
 }
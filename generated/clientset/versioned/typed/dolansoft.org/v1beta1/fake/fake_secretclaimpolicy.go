@@ -0,0 +1,106 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeSecretClaimPolicies implements SecretClaimPolicyInterface
+type FakeSecretClaimPolicies struct {
+	Fake *FakeDolansoftV1beta1
+}
+
+var secretclaimpoliciesResource = schema.GroupVersionResource{Group: "dolansoft.org", Version: "v1beta1", Resource: "secretclaimpolicies"}
+
+var secretclaimpoliciesKind = schema.GroupVersionKind{Group: "dolansoft.org", Version: "v1beta1", Kind: "SecretClaimPolicy"}
+
+// Get takes name of the secretClaimPolicy, and returns the corresponding secretClaimPolicy object, and an error if there is any.
+func (c *FakeSecretClaimPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.SecretClaimPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(secretclaimpoliciesResource, name), &v1beta1.SecretClaimPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.SecretClaimPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of SecretClaimPolicies that match those selectors.
+func (c *FakeSecretClaimPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.SecretClaimPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(secretclaimpoliciesResource, secretclaimpoliciesKind, opts), &v1beta1.SecretClaimPolicyList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.SecretClaimPolicyList{ListMeta: obj.(*v1beta1.SecretClaimPolicyList).ListMeta}
+	for _, item := range obj.(*v1beta1.SecretClaimPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested secretClaimPolicies.
+func (c *FakeSecretClaimPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(secretclaimpoliciesResource, opts))
+}
+
+// Create takes the representation of a secretClaimPolicy and creates it.  Returns the server's representation of the secretClaimPolicy, and an error, if there is any.
+func (c *FakeSecretClaimPolicies) Create(ctx context.Context, secretClaimPolicy *v1beta1.SecretClaimPolicy, opts v1.CreateOptions) (result *v1beta1.SecretClaimPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(secretclaimpoliciesResource, secretClaimPolicy), &v1beta1.SecretClaimPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.SecretClaimPolicy), err
+}
+
+// Update takes the representation of a secretClaimPolicy and updates it. Returns the server's representation of the secretClaimPolicy, and an error, if there is any.
+func (c *FakeSecretClaimPolicies) Update(ctx context.Context, secretClaimPolicy *v1beta1.SecretClaimPolicy, opts v1.UpdateOptions) (result *v1beta1.SecretClaimPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(secretclaimpoliciesResource, secretClaimPolicy), &v1beta1.SecretClaimPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.SecretClaimPolicy), err
+}
+
+// Delete takes name of the secretClaimPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeSecretClaimPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(secretclaimpoliciesResource, name, opts), &v1beta1.SecretClaimPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeSecretClaimPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(secretclaimpoliciesResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.SecretClaimPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched secretClaimPolicy.
+func (c *FakeSecretClaimPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.SecretClaimPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(secretclaimpoliciesResource, name, pt, data, subresources...), &v1beta1.SecretClaimPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.SecretClaimPolicy), err
+}
@@ -0,0 +1,152 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	scheme "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// SecretClaimPoliciesGetter has a method to return a SecretClaimPolicyInterface.
+// A group's client should implement this interface.
+type SecretClaimPoliciesGetter interface {
+	SecretClaimPolicies() SecretClaimPolicyInterface
+}
+
+// SecretClaimPolicyInterface has methods to work with SecretClaimPolicy resources.
+type SecretClaimPolicyInterface interface {
+	Create(ctx context.Context, secretClaimPolicy *v1beta1.SecretClaimPolicy, opts v1.CreateOptions) (*v1beta1.SecretClaimPolicy, error)
+	Update(ctx context.Context, secretClaimPolicy *v1beta1.SecretClaimPolicy, opts v1.UpdateOptions) (*v1beta1.SecretClaimPolicy, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.SecretClaimPolicy, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.SecretClaimPolicyList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.SecretClaimPolicy, err error)
+	SecretClaimPolicyExpansion
+}
+
+// secretClaimPolicies implements SecretClaimPolicyInterface
+type secretClaimPolicies struct {
+	client rest.Interface
+}
+
+// newSecretClaimPolicies returns a SecretClaimPolicies
+func newSecretClaimPolicies(c *DolansoftV1beta1Client) *secretClaimPolicies {
+	return &secretClaimPolicies{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the secretClaimPolicy, and returns the corresponding secretClaimPolicy object, and an error if there is any.
+func (c *secretClaimPolicies) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.SecretClaimPolicy, err error) {
+	result = &v1beta1.SecretClaimPolicy{}
+	err = c.client.Get().
+		Resource("secretclaimpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of SecretClaimPolicies that match those selectors.
+func (c *secretClaimPolicies) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.SecretClaimPolicyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.SecretClaimPolicyList{}
+	err = c.client.Get().
+		Resource("secretclaimpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested secretClaimPolicies.
+func (c *secretClaimPolicies) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("secretclaimpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a secretClaimPolicy and creates it.  Returns the server's representation of the secretClaimPolicy, and an error, if there is any.
+func (c *secretClaimPolicies) Create(ctx context.Context, secretClaimPolicy *v1beta1.SecretClaimPolicy, opts v1.CreateOptions) (result *v1beta1.SecretClaimPolicy, err error) {
+	result = &v1beta1.SecretClaimPolicy{}
+	err = c.client.Post().
+		Resource("secretclaimpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(secretClaimPolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a secretClaimPolicy and updates it. Returns the server's representation of the secretClaimPolicy, and an error, if there is any.
+func (c *secretClaimPolicies) Update(ctx context.Context, secretClaimPolicy *v1beta1.SecretClaimPolicy, opts v1.UpdateOptions) (result *v1beta1.SecretClaimPolicy, err error) {
+	result = &v1beta1.SecretClaimPolicy{}
+	err = c.client.Put().
+		Resource("secretclaimpolicies").
+		Name(secretClaimPolicy.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(secretClaimPolicy).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the secretClaimPolicy and deletes it. Returns an error if one occurs.
+func (c *secretClaimPolicies) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("secretclaimpolicies").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *secretClaimPolicies) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("secretclaimpolicies").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched secretClaimPolicy.
+func (c *secretClaimPolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.SecretClaimPolicy, err error) {
+	result = &v1beta1.SecretClaimPolicy{}
+	err = c.client.Patch(pt).
+		Resource("secretclaimpolicies").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
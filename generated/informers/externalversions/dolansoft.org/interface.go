@@ -3,6 +3,7 @@
 package dolansoft
 
 import (
+	v1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/informers/externalversions/dolansoft.org/v1"
 	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/informers/externalversions/dolansoft.org/v1beta1"
 	internalinterfaces "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/informers/externalversions/internalinterfaces"
 )
@@ -11,6 +12,8 @@ import (
 type Interface interface {
 	// V1beta1 provides access to shared informers for resources in V1beta1.
 	V1beta1() v1beta1.Interface
+	// V1 provides access to shared informers for resources in V1.
+	V1() v1.Interface
 }
 
 type group struct {
@@ -28,3 +31,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (g *group) V1beta1() v1beta1.Interface {
 	return v1beta1.New(g.factory, g.namespace, g.tweakListOptions)
 }
+
+// V1 returns a new v1.Interface.
+func (g *group) V1() v1.Interface {
+	return v1.New(g.factory, g.namespace, g.tweakListOptions)
+}
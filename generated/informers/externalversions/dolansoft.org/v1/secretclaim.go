@@ -0,0 +1,74 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	time "time"
+
+	dolansoftorgv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1"
+	versioned "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned"
+	internalinterfaces "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/informers/externalversions/internalinterfaces"
+	v1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/listers/dolansoft.org/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// SecretClaimInformer provides access to a shared informer and lister for
+// SecretClaims.
+type SecretClaimInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1.SecretClaimLister
+}
+
+type secretClaimInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewSecretClaimInformer constructs a new informer for SecretClaim type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewSecretClaimInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredSecretClaimInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredSecretClaimInformer constructs a new informer for SecretClaim type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredSecretClaimInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DolansoftV1().SecretClaims(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DolansoftV1().SecretClaims(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&dolansoftorgv1.SecretClaim{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *secretClaimInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredSecretClaimInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *secretClaimInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&dolansoftorgv1.SecretClaim{}, f.defaultInformer)
+}
+
+func (f *secretClaimInformer) Lister() v1.SecretClaimLister {
+	return v1.NewSecretClaimLister(f.Informer().GetIndexer())
+}
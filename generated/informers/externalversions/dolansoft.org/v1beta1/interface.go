@@ -10,6 +10,8 @@ import (
 type Interface interface {
 	// SecretClaims returns a SecretClaimInformer.
 	SecretClaims() SecretClaimInformer
+	// SecretClaimPolicies returns a SecretClaimPolicyInformer.
+	SecretClaimPolicies() SecretClaimPolicyInformer
 }
 
 type version struct {
@@ -27,3 +29,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (v *version) SecretClaims() SecretClaimInformer {
 	return &secretClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// SecretClaimPolicies returns a SecretClaimPolicyInformer.
+func (v *version) SecretClaimPolicies() SecretClaimPolicyInformer {
+	return &secretClaimPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
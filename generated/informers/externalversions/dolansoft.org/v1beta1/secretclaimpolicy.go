@@ -0,0 +1,73 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	time "time"
+
+	dolansoftorgv1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	versioned "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned"
+	internalinterfaces "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/informers/externalversions/internalinterfaces"
+	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/listers/dolansoft.org/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// SecretClaimPolicyInformer provides access to a shared informer and lister for
+// SecretClaimPolicies.
+type SecretClaimPolicyInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.SecretClaimPolicyLister
+}
+
+type secretClaimPolicyInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewSecretClaimPolicyInformer constructs a new informer for SecretClaimPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewSecretClaimPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredSecretClaimPolicyInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredSecretClaimPolicyInformer constructs a new informer for SecretClaimPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredSecretClaimPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DolansoftV1beta1().SecretClaimPolicies().List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DolansoftV1beta1().SecretClaimPolicies().Watch(context.TODO(), options)
+			},
+		},
+		&dolansoftorgv1beta1.SecretClaimPolicy{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *secretClaimPolicyInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredSecretClaimPolicyInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *secretClaimPolicyInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&dolansoftorgv1beta1.SecretClaimPolicy{}, f.defaultInformer)
+}
+
+func (f *secretClaimPolicyInformer) Lister() v1beta1.SecretClaimPolicyLister {
+	return v1beta1.NewSecretClaimPolicyLister(f.Informer().GetIndexer())
+}
@@ -5,6 +5,7 @@ package externalversions
 import (
 	"fmt"
 
+	v1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1"
 	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
@@ -36,9 +37,15 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=dolansoft.org, Version=v1beta1
+	// Group=dolansoft.org, Version=v1
+	case v1.SchemeGroupVersion.WithResource("secretclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dolansoft().V1().SecretClaims().Informer()}, nil
+
+		// Group=dolansoft.org, Version=v1beta1
 	case v1beta1.SchemeGroupVersion.WithResource("secretclaims"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Dolansoft().V1beta1().SecretClaims().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("secretclaimpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dolansoft().V1beta1().SecretClaimPolicies().Informer()}, nil
 
 	}
 
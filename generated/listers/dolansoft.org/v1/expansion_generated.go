@@ -0,0 +1,11 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+// SecretClaimListerExpansion allows custom methods to be added to
+// SecretClaimLister.
+type SecretClaimListerExpansion interface{}
+
+// SecretClaimNamespaceListerExpansion allows custom methods to be added to
+// SecretClaimNamespaceLister.
+type SecretClaimNamespaceListerExpansion interface{}
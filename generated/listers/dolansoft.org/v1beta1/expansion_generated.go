@@ -9,3 +9,7 @@ type SecretClaimListerExpansion interface{}
 // SecretClaimNamespaceListerExpansion allows custom methods to be added to
 // SecretClaimNamespaceLister.
 type SecretClaimNamespaceListerExpansion interface{}
+
+// SecretClaimPolicyListerExpansion allows custom methods to be added to
+// SecretClaimPolicyLister.
+type SecretClaimPolicyListerExpansion interface{}
@@ -0,0 +1,52 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SecretClaimPolicyLister helps list SecretClaimPolicies.
+// All objects returned here must be treated as read-only.
+type SecretClaimPolicyLister interface {
+	// List lists all SecretClaimPolicies in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.SecretClaimPolicy, err error)
+	// Get retrieves the SecretClaimPolicy from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.SecretClaimPolicy, error)
+	SecretClaimPolicyListerExpansion
+}
+
+// secretClaimPolicyLister implements the SecretClaimPolicyLister interface.
+type secretClaimPolicyLister struct {
+	indexer cache.Indexer
+}
+
+// NewSecretClaimPolicyLister returns a new SecretClaimPolicyLister.
+func NewSecretClaimPolicyLister(indexer cache.Indexer) SecretClaimPolicyLister {
+	return &secretClaimPolicyLister{indexer: indexer}
+}
+
+// List lists all SecretClaimPolicies in the indexer.
+func (s *secretClaimPolicyLister) List(selector labels.Selector) (ret []*v1beta1.SecretClaimPolicy, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.SecretClaimPolicy))
+	})
+	return ret, err
+}
+
+// Get retrieves the SecretClaimPolicy from the index for a given name.
+func (s *secretClaimPolicyLister) Get(name string) (*v1beta1.SecretClaimPolicy, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("secretclaimpolicy"), name)
+	}
+	return obj.(*v1beta1.SecretClaimPolicy), nil
+}
@@ -27,6 +27,10 @@ CODEGEN_PKG=$GOPATH/src/k8s.io/code-generator
 #                  instead of the $GOPATH directly. For normal projects this can be dropped.
 bash "${CODEGEN_PKG}"/generate-groups.sh "deepcopy,client,informer,lister" \
   git.dolansoft.org/dolansoft/k8s-generic-secrets/generated git.dolansoft.org/dolansoft/k8s-generic-secrets/apis \
-  dolansoft.org:v1beta1 \
+  dolansoft.org:v1beta1,v1 \
   --output-base "$GOPATH/src/" \
-  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
\ No newline at end of file
+  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
+
+# generate the CRD manifests from the types and their +kubebuilder markers
+CONTROLLER_GEN=${CONTROLLER_GEN:-controller-gen}
+(cd "${SCRIPT_ROOT}" && "${CONTROLLER_GEN}" crd:crdVersions=v1 paths=./apis/... output:crd:artifacts:config=crds)
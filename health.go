@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+// healthChecks backs the /healthz and /readyz endpoints. Liveness fails if this replica is the leader but has not
+// been able to renew its lease for a while. Readiness fails while the leader is still syncing its informers. Replicas
+// which are waiting for leadership are live and ready as they are able to take over at any time.
+type healthChecks struct {
+	leaderElection *leaderelection.HealthzAdaptor
+	leading        int32
+	cacheSynced    func() bool
+}
+
+func newHealthChecks(cacheSynced func() bool) *healthChecks {
+	return &healthChecks{
+		leaderElection: leaderelection.NewLeaderHealthzAdaptor(20 * time.Second),
+		cacheSynced:    cacheSynced,
+	}
+}
+
+func (h *healthChecks) setLeading(leading bool) {
+	var v int32
+	if leading {
+		v = 1
+	}
+	atomic.StoreInt32(&h.leading, v)
+}
+
+func (h *healthChecks) isLeading() bool {
+	return atomic.LoadInt32(&h.leading) == 1
+}
+
+func (h *healthChecks) healthz(w http.ResponseWriter, r *http.Request) {
+	if err := h.leaderElection.Check(r); err != nil {
+		http.Error(w, fmt.Sprintf("leader election: %v", err), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+func (h *healthChecks) readyz(w http.ResponseWriter, r *http.Request) {
+	if !h.isLeading() {
+		fmt.Fprintln(w, "ok (standby)")
+		return
+	}
+	if !h.cacheSynced() {
+		http.Error(w, "informer caches not synced", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok (leader)")
+}
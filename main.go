@@ -7,39 +7,63 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base32"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
-	"encoding/json"
 	"encoding/pem"
 	"flag"
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
 	clientset "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned"
+	dsscheme "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/scheme"
 	informers "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/informers/externalversions"
-	"git.dolansoft.org/dolansoft/k8s-generic-secrets/jsonpatch"
+	listers "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/listers/dolansoft.org/v1beta1"
 	"github.com/google/uuid"
-	"k8s.io/apimachinery/pkg/api/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/util/csaupgrade"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
-	"maze.io/x/duration"
 )
 
 const (
@@ -57,13 +81,45 @@ var (
 		"The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	kubeconfig = flag.String("kubeconfig", "",
 		"Path to a kubeconfig. Only required if out-of-cluster.")
-	clusterDomain = flag.String("cluster-domain", "cluster.local", "Kubernetes DNS cluster domain (default cluster.local)")
+	clusterDomain   = flag.String("cluster-domain", "cluster.local", "Kubernetes DNS cluster domain (default cluster.local)")
+	workers         = flag.Int("workers", 2, "Number of claims reconciled concurrently")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 20*time.Second,
+		"Time to wait for in-flight reconciles to finish on shutdown before cancelling them")
+	httpAddress         = flag.String("http-address", ":8080", "Address on which to serve the /metrics, /healthz and /readyz endpoints")
+	minTokenEntropyBits = flag.Int("min-token-entropy-bits", 0,
+		"Minimum entropy in bits of custom tokens which don't set allowLowEntropy, 0 disables the check")
+
+	webhookAddress  = flag.String("webhook-address", "", "Address on which to serve the validating admission and conversion webhooks over HTTPS, disabled if empty")
+	webhookCertFile = flag.String("webhook-cert-file", "/etc/k8s-generic-secrets/webhook/tls.crt", "Path to the PEM-encoded certificate of the webhook")
+	webhookKeyFile  = flag.String("webhook-key-file", "/etc/k8s-generic-secrets/webhook/tls.key", "Path to the PEM-encoded private key of the webhook")
+
+	leaderElect = flag.Bool("leader-elect", true,
+		"Use leader election so that only one of multiple replicas reconciles claims at any time")
+	leaseName          = flag.String("leader-elect-lease-name", "k8s-generic-secrets", "Name of the Lease object used for leader election")
+	leaseNamespace     = flag.String("leader-elect-namespace", "kube-system", "Namespace of the Lease object used for leader election")
+	leaseDuration      = flag.Duration("leader-elect-lease-duration", 15*time.Second, "Duration standby replicas wait before taking over leadership")
+	leaseRenewDeadline = flag.Duration("leader-elect-renew-deadline", 10*time.Second, "Duration the leader retries renewing its lease before giving up leadership")
+	leaseRetryPeriod   = flag.Duration("leader-elect-retry-period", 2*time.Second, "Duration between leader election attempts")
 )
 
 type controller struct {
-	kclient  *kubernetes.Clientset
-	dsclient *clientset.Clientset
-	queue    workqueue.RateLimitingInterface
+	kclient         kubernetes.Interface
+	dsclient        clientset.Interface
+	scLister        listers.SecretClaimLister
+	scIndexer       cache.Indexer
+	namespaceLister corelisters.NamespaceLister
+	configMapLister corelisters.ConfigMapLister
+	policyLister    listers.SecretClaimPolicyLister
+	// secretLister only contains secrets labeled with LabelClaimUID, all other secrets are fetched by getSecret
+	secretLister corelisters.SecretLister
+	// secretMetadataLister contains the metadata of all secrets
+	secretMetadataLister cache.GenericLister
+	// unmanagedSecrets caches secrets without LabelClaimUID by namespace/name until their resourceVersion changes
+	unmanagedSecrets sync.Map
+	// minTokenEntropyBits is the minimum entropy of custom tokens, see SecretClaimSpec.ValidateMinEntropy
+	minTokenEntropyBits int32
+	queue               workqueue.RateLimitingInterface
+	recorder            events.EventRecorder
 }
 
 func (c *controller) enqueueSC(obj interface{}) {
@@ -74,9 +130,50 @@ func (c *controller) enqueueSC(obj interface{}) {
 	c.queue.Add(key)
 }
 
+// enqueueOwnerSC enqueues the SecretClaim controlling a secret or replicating into it, if any.
+func (c *controller) enqueueOwnerSC(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	if key, ok := replicaSource(secret); ok {
+		c.queue.Add(key)
+		return
+	}
+	ownerRef := secretClaimOwner(secret)
+	if ownerRef == nil {
+		return
+	}
+	c.queue.Add(secret.Namespace + "/" + ownerRef.Name)
+}
+
+// secretClaimOwner returns the controller OwnerReference of a secret if it points to a SecretClaim.
+func secretClaimOwner(secret *corev1.Secret) *metav1.OwnerReference {
+	ownerRef := metav1.GetControllerOf(secret)
+	if ownerRef == nil || ownerRef.Kind != v1beta1.Kind {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+	if err != nil || gv.Group != v1beta1.GroupName {
+		return nil
+	}
+	return ownerRef
+}
+
+func (c *controller) enqueueSCAfter(obj interface{}, after time.Duration) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		panic(err)
+	}
+	c.queue.AddAfter(key, after)
+}
+
 // processQueueItems gets items from the given work queue and calls the process function for each of them. It self-
 // terminates once the queue is shut down.
-func (c *controller) processQueueItems(queue workqueue.RateLimitingInterface, process func(key string) error) {
+func (c *controller) processQueueItems(ctx context.Context, queue workqueue.RateLimitingInterface, process func(ctx context.Context, key string) error) {
 	for {
 		obj, shutdown := queue.Get()
 		if shutdown {
@@ -92,9 +189,10 @@ func (c *controller) processQueueItems(queue workqueue.RateLimitingInterface, pr
 				return
 			}
 
-			if err := process(key); err != nil {
+			if err := process(ctx, key); err != nil {
 				log.Printf("Failed processing item \"%v\", requeueing (%v tries): %v", key, queue.NumRequeues(obj), err)
 				queue.AddRateLimited(obj)
+				return
 			}
 
 			queue.Forget(obj)
@@ -103,56 +201,86 @@ func (c *controller) processQueueItems(queue workqueue.RateLimitingInterface, pr
 }
 
 func (c *controller) certFromSecret(ctx context.Context, namespace string, name string) (*x509.Certificate, crypto.PrivateKey, error) {
-	caSecret, err := c.kclient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	caSecret, err := c.getSecret(ctx, namespace, name)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get caSecret: %w", err)
 	}
+	if caSecret == nil {
+		return nil, nil, fmt.Errorf("failed to get caSecret: %w", apierrors.NewNotFound(corev1.Resource("secrets"), name))
+	}
 	caCertPEM := caSecret.Data["ca.crt"]
 	caCertBlock, _ := pem.Decode(caCertPEM)
 	if caCertBlock == nil {
-		return nil, nil, fmt.Errorf("\"ca.crt\" contains no PEM data in secret \"%s\": %w", name, err)
+		return nil, nil, fmt.Errorf("\"ca.crt\" contains no PEM data in secret \"%s\"", name)
 	}
 	caCert, err := x509.ParseCertificate(caCertBlock.Bytes)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse \"ca.crt\" certificate: %w", err)
 	}
-	caKeyPEM := caSecret.Data["ca.key"]
-	caKeyBlock, _ := pem.Decode(caKeyPEM)
-	if caCertBlock == nil {
-		return nil, nil, fmt.Errorf("\"ca.key\" contains no PEM data in secret \"%s\": %w", name, err)
-	}
-	var caKey crypto.PrivateKey
-	if caKeyBlock.Type == "EC PRIVATE KEY" {
-		caKey, err = x509.ParseECPrivateKey(caKeyBlock.Bytes)
-	} else if caKeyBlock.Type == "PRIVATE KEY" {
-		caKey, err = x509.ParsePKCS8PrivateKey(caKeyBlock.Bytes)
-	} else {
-		return nil, nil, fmt.Errorf("unknown PEM block type \"%s\" in private key", caKeyBlock.Type)
-	}
+	caKey, _, err := parsePrivateKeyPEM(caSecret.Data["ca.key"])
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse \"ca.key\" key: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse \"ca.key\" in secret \"%s\": %w", name, err)
 	}
 	return caCert, caKey, nil
 }
 
-func (c *controller) issueCertificate(ctx context.Context, claim *v1beta1.SecretClaim, data map[string][]byte) error {
-	x509spec := claim.Spec.X509Claim
-	var commonName string = x509spec.CommonName
-	if commonName == "" {
-		commonName = claim.Name
+// PEM block types of private keys
+const (
+	keyType       = "PRIVATE KEY"
+	legacyKeyType = "EC PRIVATE KEY"
+)
+
+// parsePrivateKeyPEM parses a PKCS #8 or legacy SEC 1 private key and returns it with its PEM block type.
+func parsePrivateKeyPEM(keyPEM []byte) (crypto.PrivateKey, string, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, "", fmt.Errorf("no PEM data found")
+	}
+	var key crypto.PrivateKey
+	var err error
+	switch block.Type {
+	case legacyKeyType:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case keyType:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, "", fmt.Errorf("unknown PEM block type \"%s\" in private key", block.Type)
+	}
+	return key, block.Type, err
+}
+
+func certificateCommonName(claim *v1beta1.SecretClaim) string {
+	if claim.Spec.X509Claim.CommonName != "" {
+		return claim.Spec.X509Claim.CommonName
 	}
+	return claim.Name
+}
+
+func certificateDNSNames(claim *v1beta1.SecretClaim) []string {
+	var dnsNames []string
+	for _, svc := range claim.Spec.X509Claim.ServiceNames {
+		dnsNames = append(dnsNames, svc, svc+"."+claim.Namespace, svc+"."+claim.Namespace+".svc."+*clusterDomain)
+	}
+	for _, extraName := range claim.Spec.X509Claim.ExtraNames {
+		dnsNames = append(dnsNames, extraName)
+	}
+	return dnsNames
+}
+
+func (c *controller) issueCertificate(ctx context.Context, claim *v1beta1.SecretClaim, data map[string][]byte) (*x509.Certificate, error) {
+	x509spec := claim.Spec.X509Claim
 	var notAfter time.Time = unknownNotAfter
 	if x509spec.RotateEvery != "" {
-		d, err := duration.ParseDuration(x509spec.RotateEvery)
+		d, err := v1beta1.ParseRotateEvery(x509spec.RotateEvery)
 		if err != nil {
-			return fmt.Errorf("cannot parse rotateEvery duration: %w", err)
+			return nil, withReason(reasonInvalidSpec, fmt.Errorf("cannot parse rotateEvery duration: %w", err))
 		}
-		notAfter = time.Now().Add(time.Duration(d))
+		notAfter = time.Now().Add(d)
 	}
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 127)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
-		return fmt.Errorf("failed to generate serial number: %v", err)
+		return nil, withReason(reasonIssuanceFailed, fmt.Errorf("failed to generate serial number: %v", err))
 	}
 
 	var keyUsage x509.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
@@ -165,18 +293,10 @@ func (c *controller) issueCertificate(ctx context.Context, claim *v1beta1.Secret
 		eku = nil
 	}
 
-	var dnsNames []string
-	for _, svc := range x509spec.ServiceNames {
-		dnsNames = append(dnsNames, svc, svc+"."+claim.Namespace, svc+"."+claim.Namespace+".svc."+*clusterDomain)
-	}
-	for _, extraName := range x509spec.ExtraNames {
-		dnsNames = append(dnsNames, extraName)
-	}
-
 	template := &x509.Certificate{
 		SerialNumber: serialNumber,
 		Subject: pkix.Name{
-			CommonName: commonName,
+			CommonName: certificateCommonName(claim),
 		},
 		NotBefore:             time.Now(),
 		NotAfter:              notAfter,
@@ -184,12 +304,12 @@ func (c *controller) issueCertificate(ctx context.Context, claim *v1beta1.Secret
 		IsCA:                  x509spec.IsCA,
 		KeyUsage:              keyUsage,
 		ExtKeyUsage:           eku,
-		DNSNames:              dnsNames,
+		DNSNames:              certificateDNSNames(claim),
 	}
 
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		return fmt.Errorf("failed to generate key: %w", err)
+		return nil, withReason(reasonIssuanceFailed, fmt.Errorf("failed to generate key: %w", err))
 	}
 
 	var certRaw []byte
@@ -197,53 +317,73 @@ func (c *controller) issueCertificate(ctx context.Context, claim *v1beta1.Secret
 	if x509spec.CASecretName == "" {
 		certRaw, err = x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
 		if err != nil {
-			return fmt.Errorf("failed to sign certificate: %w", err)
+			return nil, withReason(reasonIssuanceFailed, fmt.Errorf("failed to sign certificate: %w", err))
 		}
 	} else {
 		caCert, caKey, err := c.certFromSecret(ctx, claim.Namespace, x509spec.CASecretName)
 		if err != nil {
-			return err
+			return nil, withReason(reasonCAUnavailable, err)
 		}
 		certRaw, err = x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
 		if err != nil {
-			return fmt.Errorf("failed to sign certificate: %w", err)
+			return nil, withReason(reasonIssuanceFailed, fmt.Errorf("failed to sign certificate: %w", err))
 		}
 	}
 
 	var keyRaw []byte
-	var keyType string
+	blockType := keyType
 	if x509spec.LegacySEC1PrivateKey {
 		keyRaw, err = x509.MarshalECPrivateKey(key)
-		keyType = "EC PRIVATE KEY"
+		blockType = legacyKeyType
 	} else {
 		keyRaw, err = x509.MarshalPKCS8PrivateKey(key)
-		keyType = "PRIVATE KEY"
 	}
 	if err != nil {
-		return fmt.Errorf("cannot marshal EC private key: %w", err)
+		return nil, withReason(reasonIssuanceFailed, fmt.Errorf("cannot marshal EC private key: %w", err))
 	}
 
 	if x509spec.IsCA {
 		data["ca.crt"] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certRaw})
-		data["ca.key"] = pem.EncodeToMemory(&pem.Block{Type: keyType, Bytes: keyRaw})
+		data["ca.key"] = pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: keyRaw})
 	} else {
 		data["tls.crt"] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certRaw})
-		data["tls.key"] = pem.EncodeToMemory(&pem.Block{Type: keyType, Bytes: keyRaw})
+		data["tls.key"] = pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: keyRaw})
 	}
-	return nil
+	cert, err := x509.ParseCertificate(certRaw)
+	if err != nil {
+		return nil, withReason(reasonIssuanceFailed, fmt.Errorf("failed to parse issued certificate: %w", err))
+	}
+	return cert, nil
 }
 
-func makeCustomToken(spec *v1beta1.CustomTokenSpec) (string, error) {
-	if spec.Length > 1*1024*1024 {
-		return "", fmt.Errorf("refusing to issue tokens larger than 1 MiB")
+// randomIndex returns a uniformly distributed random number in [0, n).
+func randomIndex(n int) (int, error) {
+	// Largest multiple of n not exceeding 2^32, values above it are rejected to avoid modulo bias
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(n)
+	raw := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+			return 0, fmt.Errorf("unable to acquire randomness: %w", err)
+		}
+		randomNum := uint64(binary.LittleEndian.Uint32(raw))
+		if randomNum >= limit {
+			continue
+		}
+		return int(randomNum % uint64(n)), nil
+	}
+}
+
+// makeCustomToken generates a token for the spec. Passphrases are made up of words from wordList or, if it is nil, the
+// EFF large word list.
+func makeCustomToken(spec *v1beta1.CustomTokenSpec, wordList []string) (string, error) {
+	if errs := spec.Validate(nil); len(errs) > 0 {
+		return "", errs.ToAggregate()
 	}
 	var strb strings.Builder
 	strb.WriteString(spec.Prefix)
 	var raw []byte
-	if spec.Encoding != "uuid" {
-		if spec.Length < 1 {
-			return "", fmt.Errorf("length needs to be at least 1")
-		}
+	switch spec.Encoding {
+	case "base64", "base64url", "hex", "upperhex", "raw", "base32", "crockford", "base58", "z85":
 		raw = make([]byte, spec.Length)
 		if _, err := io.ReadFull(rand.Reader, raw); err != nil {
 			return "", fmt.Errorf("unable to acquire randomness: %w", err)
@@ -260,31 +400,52 @@ func makeCustomToken(spec *v1beta1.CustomTokenSpec) (string, error) {
 		strb.WriteString(strings.ToUpper(hex.EncodeToString(raw)))
 	case "characterset":
 		charset := []rune(spec.CharacterSet)
-		if len(charset) == 0 {
-			return "", fmt.Errorf("for encoding characterset at least one character needs to be in the characterset")
+		for i := 0; i < int(spec.Length); i++ {
+			n, err := randomIndex(len(charset))
+			if err != nil {
+				return "", err
+			}
+			strb.WriteRune(charset[n])
 		}
-		if len(charset) >= math.MaxUint32 {
-			return "", fmt.Errorf("character set needs to be smaller than 2^32 runes")
+	case "passwordpolicy":
+		password, err := makePassword(spec.PasswordPolicy, int(spec.Length))
+		if err != nil {
+			return "", err
 		}
-		largestValidValue := math.MaxUint32 - (math.MaxUint32 % uint32(len(charset)))
-
-		for i := 0; i < int(spec.Length); {
-			raw := make([]byte, 4)
-			if _, err := io.ReadFull(rand.Reader, raw); err != nil {
-				return "", fmt.Errorf("unable to acquire randomness: %w", err)
-			}
-			randomNum := binary.LittleEndian.Uint32(raw)
-			if randomNum > largestValidValue {
-				// Avoid modulo bias
-				continue
-			}
-			strb.WriteRune(charset[randomNum%uint32(len(charset))])
-			i++
+		strb.WriteString(password)
+	case "passphrase":
+		if wordList == nil {
+			wordList = effLargeWordList
 		}
+		passphrase, err := makePassphrase(spec, wordList)
+		if err != nil {
+			return "", err
+		}
+		strb.WriteString(passphrase)
 	case "raw":
 		strb.Write(raw)
 	case "uuid":
 		strb.WriteString(uuid.New().String())
+	case "base32":
+		strb.WriteString(base32.StdEncoding.EncodeToString(raw))
+	case "crockford":
+		strb.WriteString(crockfordEncoding.EncodeToString(raw))
+	case "base58":
+		strb.WriteString(encodeBase58(raw))
+	case "z85":
+		strb.WriteString(encodeZ85(raw))
+	case "ulid":
+		id, err := newULID(time.Now())
+		if err != nil {
+			return "", err
+		}
+		strb.WriteString(id)
+	case "uuidv7":
+		id, err := newUUIDv7(time.Now())
+		if err != nil {
+			return "", err
+		}
+		strb.WriteString(id)
 	default:
 		return "", fmt.Errorf("unknown token format %q", spec.Encoding)
 	}
@@ -292,7 +453,7 @@ func makeCustomToken(spec *v1beta1.CustomTokenSpec) (string, error) {
 	return strb.String(), nil
 }
 
-func customTokenValid(spec *v1beta1.CustomTokenSpec, token string) bool {
+func customTokenValid(spec *v1beta1.CustomTokenSpec, wordList []string, token string) bool {
 	if !strings.HasPrefix(token, spec.Prefix) {
 		return false
 	}
@@ -361,113 +522,686 @@ func customTokenValid(spec *v1beta1.CustomTokenSpec, token string) bool {
 		if err != nil {
 			return false
 		}
+	case "passwordpolicy":
+		return spec.PasswordPolicy != nil && passwordValid(spec.PasswordPolicy, int(spec.Length), innerToken)
+	case "passphrase":
+		if wordList == nil {
+			wordList = effLargeWordList
+		}
+		return passphraseValid(spec, wordList, innerToken)
+	case "base32":
+		return decodeCanonical(base32.StdEncoding, innerToken, int(spec.Length))
+	case "crockford":
+		return decodeCanonical(crockfordEncoding, innerToken, int(spec.Length))
+	case "base58":
+		out, err := decodeBase58(innerToken)
+		return err == nil && len(out) == int(spec.Length) && encodeBase58(out) == innerToken
+	case "z85":
+		out, err := decodeZ85(innerToken)
+		return err == nil && len(out) == int(spec.Length)
+	case "ulid":
+		return ulidValid(innerToken)
+	case "uuidv7":
+		return uuidv7Valid(innerToken)
 	default:
 		return false
 	}
 	return true
 }
 
-func (c *controller) reconcileSC(key string) error {
+// run starts the given number of workers and blocks until ctx is cancelled. It then shuts down the queue and waits for
+// the workers to finish all remaining items. If they take longer than shutdownTimeout, the context passed to in-flight
+// reconciles is cancelled.
+func (c *controller) run(ctx context.Context, workers int, shutdownTimeout time.Duration) {
+	workerCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.processQueueItems(workerCtx, c.queue, c.reconcileSC)
+		}()
+	}
+	<-ctx.Done()
+
+	log.Printf("Shutting down, waiting up to %v for workers to drain the queue", shutdownTimeout)
+	drained := make(chan struct{})
+	go func() {
+		c.queue.ShutDownWithDrain()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(shutdownTimeout):
+		log.Printf("Workers did not finish in time, cancelling in-flight reconciles")
+		cancel()
+	}
+	wg.Wait()
+}
+
+func (c *controller) reconcileSC(ctx context.Context, key string) (err error) {
+	start := time.Now()
+	defer func() {
+		observeReconcile(start, err)
+	}()
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		panic(err)
 	}
-	ctx := context.Background()
-	sc, err := c.dsclient.DolansoftV1beta1().SecretClaims(namespace).Get(context.Background(), name, metav1.GetOptions{})
-	if errors.IsNotFound(err) {
+	cachedSC, err := c.scLister.SecretClaims(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
 		return nil // Nothing to reconcile
 	}
 	if err != nil {
 		return err
 	}
-	oldSecret, err := c.kclient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
-	if errors.IsNotFound(err) {
-		newData := make(map[string][]byte)
-		if sc.Spec.X509Claim != nil {
-			if err := c.issueCertificate(ctx, sc, newData); err != nil {
-				return fmt.Errorf("failed to issue certificate: %w", err)
-			}
+	// Objects from the cache are shared and must not be modified
+	sc := cachedSC.DeepCopy()
+	if sc.DeletionTimestamp != nil {
+		return c.finalizeSC(ctx, sc)
+	}
+	sc, err = c.syncFinalizers(ctx, sc)
+	if err != nil {
+		return err
+	}
+	status := sc.Status.DeepCopy()
+	// Defaults are only applied to the copy which is reconciled and never written back to the claim
+	desired := sc.DeepCopy()
+	desired.Default()
+	var reconcileErr error
+	if errs := desired.Validate(); len(errs) > 0 {
+		reconcileErr = withReason(reasonInvalidSpec, errs.ToAggregate())
+	} else {
+		// The entropy of passphrases depends on the size of their word lists, so they are resolved first
+		wordLists, err := c.referencedWordLists(desired)
+		sizes := wordListSizes(wordLists)
+		status.TokenEntropyBits = desired.Spec.TokenEntropyBits(sizes)
+		if err != nil {
+			reconcileErr = err
+		} else if errs := desired.Spec.ValidateMinEntropy(c.minTokenEntropyBits, sizes); len(errs) > 0 {
+			reconcileErr = withReason(reasonInsufficientEntropy, errs.ToAggregate())
+		} else if err := c.checkPolicies(desired, sizes); err != nil {
+			reconcileErr = err
 		} else {
-			for k, v := range sc.Spec.FixedFields {
-				newData[k] = []byte(v)
-			}
-			for _, field := range sc.Spec.TokenFields {
-				newToken := make([]byte, tokenLength)
-				if _, err := io.ReadFull(rand.Reader, newToken); err != nil {
-					return fmt.Errorf("failed to read randomness: %w", err)
-				}
-				newData[field] = []byte(hex.EncodeToString(newToken))
+			reconcileErr = c.reconcileSecret(ctx, desired, status, wordLists)
+		}
+	}
+	if reconcileErr != nil {
+		setCondition(sc, status, v1beta1.ConditionReady, metav1.ConditionFalse, errorReason(reconcileErr), reconcileErr.Error())
+		c.recorder.Eventf(sc, nil, corev1.EventTypeWarning, errorReason(reconcileErr), "Reconcile", "%v", reconcileErr)
+	} else {
+		setCondition(sc, status, v1beta1.ConditionReady, metav1.ConditionTrue, reasonReconciled, "Secret is up to date")
+	}
+	status.ObservedGeneration = sc.Generation
+	if err := c.updateStatus(ctx, sc, status); err != nil {
+		if reconcileErr == nil {
+			return err
+		}
+		log.Printf("Failed to update status of \"%v\": %v", key, err)
+	}
+	return reconcileErr
+}
+
+// reconcileSecret applies the desired state of the secret belonging to a claim and records the results in status.
+// wordLists are the resolved word lists of passphrases by field, see referencedWordLists.
+func (c *controller) reconcileSecret(ctx context.Context, sc *v1beta1.SecretClaim, status *v1beta1.SecretClaimStatus, wordLists map[string][]string) error {
+	namespace, name := sc.Namespace, secretName(sc)
+	if sc.Spec.X509Claim == nil {
+		clearCertificateStatus(status)
+	}
+	var oldSecret *corev1.Secret
+	var adopting bool
+	var err error
+	if !sc.Spec.Immutable {
+		oldSecret, err = c.getSecret(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		if oldSecret != nil && !metav1.IsControlledBy(oldSecret, sc) {
+			if err := checkAdoption(sc, oldSecret); err != nil {
+				return err
 			}
-			for field, spec := range sc.Spec.CustomTokenFields {
-				token, err := makeCustomToken(&spec)
-				newData[field] = []byte(token)
-				if err != nil {
-					return err
-				}
+			adopting = true
+		}
+	}
+	// Values are taken over from the previous secret of the claim if the secret has been renamed or a new immutable
+	// generation is created.
+	existing := oldSecret
+	if existing == nil {
+		existing, err = c.previousSecret(ctx, sc, status.SecretName)
+		if err != nil {
+			return err
+		}
+	}
+	var data map[string][]byte
+	var changedKeys []string
+	var issuedCert *x509.Certificate
+	if sc.Spec.X509Claim != nil {
+		data, issuedCert, err = c.reconcileCertificate(ctx, sc, existing, status)
+		if issuedCert != nil {
+			for k := range data {
+				changedKeys = append(changedKeys, k)
 			}
 		}
-		newSecret := corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:            name,
-				Namespace:       namespace,
-				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sc, schema.GroupVersionKind{Group: v1beta1.GroupName, Kind: v1beta1.Kind, Version: v1beta1.Version})},
-			},
-			Data: newData,
+	} else {
+		var referenced map[string][]byte
+		referenced, err = c.referencedValues(ctx, sc)
+		if err == nil {
+			data, changedKeys, err = desiredFields(sc, existing, referenced, wordLists)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if sc.Spec.Immutable {
+		name = immutableSecretName(sc, data)
+		oldSecret, err = c.getSecret(ctx, namespace, name)
+		if err != nil {
+			return err
 		}
-		if _, err := c.kclient.CoreV1().Secrets(namespace).Create(ctx, &newSecret, metav1.CreateOptions{FieldManager: fieldManager}); err != nil {
-			return fmt.Errorf("failed to create new secret: %w", err)
+		if oldSecret != nil && !metav1.IsControlledBy(oldSecret, sc) {
+			return withReason(reasonSecretConflict, fmt.Errorf("secret \"%s\" already exists and is not owned by this claim", name))
+		}
+	}
+	if oldSecret != nil && !adopting && secretTypeOf(oldSecret) != secretType(sc) {
+		if err := c.deleteForTypeChange(ctx, sc, oldSecret); err != nil {
+			return err
 		}
+		oldSecret = nil
+	}
+	if err := c.applySecret(ctx, sc, name, oldSecret, data, adopting, changedKeys); err != nil {
+		return err
+	}
+	if issuedCert != nil {
+		c.setCertificateStatus(sc, status, issuedCert, true)
+	}
+	if status.SecretName != "" && status.SecretName != name {
+		if err := c.removePreviousSecret(ctx, sc, status.SecretName); err != nil {
+			return err
+		}
+	}
+	status.SecretName = name
+	if sc.Spec.Immutable {
+		if err := c.collectGenerations(ctx, sc, name); err != nil {
+			return err
+		}
+	}
+	return c.reconcileReplicas(ctx, sc, status, name, data)
+}
+
+// getSecret returns a secret or nil if it does not exist. Secrets written by the controller are served from the cache.
+// Other secrets, like CA secrets, referenced secrets or secrets which are about to be adopted, are fetched from the API
+// server and kept until the metadata cache reports a newer resourceVersion.
+func (c *controller) getSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	key := namespace + "/" + name
+	secret, err := c.secretLister.Secrets(namespace).Get(name)
+	if err == nil {
+		c.unmanagedSecrets.Delete(key)
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	obj, err := c.secretMetadataLister.ByNamespace(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		c.unmanagedSecrets.Delete(key)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := c.unmanagedSecrets.Load(key); ok && cached.(*corev1.Secret).ResourceVersion == objMeta.GetResourceVersion() {
+		return cached.(*corev1.Secret), nil
+	}
+	secret, err = c.kclient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		c.unmanagedSecrets.Delete(key)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret \"%s\": %w", name, err)
+	}
+	c.unmanagedSecrets.Store(key, secret)
+	return secret, nil
+}
+
+// listSecretMetadata returns secrets which only have their metadata set from the metadata cache. Unlike secretLister,
+// it also contains secrets written by older versions of the controller which lack LabelClaimUID.
+func (c *controller) listSecretMetadata(selector labels.Selector) ([]*corev1.Secret, error) {
+	objs, err := c.secretMetadataLister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]*corev1.Secret, 0, len(objs))
+	for _, obj := range objs {
+		secrets = append(secrets, secretFromMetadata(obj))
+	}
+	return secrets, nil
+}
+
+// secretFromMetadata converts an object from the metadata cache, or a tombstone of one, to a secret without data so that
+// it can be passed to the event handlers of secrets.
+func secretFromMetadata(obj interface{}) *corev1.Secret {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	objMeta, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
 		return nil
 	}
+	return &corev1.Secret{ObjectMeta: objMeta.ObjectMeta}
+}
+
+// previousSecret returns the secret previously generated for a claim if it is still controlled by the claim or an
+// empty secret otherwise.
+func (c *controller) previousSecret(ctx context.Context, sc *v1beta1.SecretClaim, name string) (*corev1.Secret, error) {
+	if name == "" {
+		return &corev1.Secret{}, nil
+	}
+	secret, err := c.getSecret(ctx, sc.Namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || !metav1.IsControlledBy(secret, sc) {
+		return &corev1.Secret{}, nil
+	}
+	return secret, nil
+}
+
+// immutableSecretName returns the name of the immutable secret holding a generation of the data of a claim. It is
+// derived from a hash over the data and metadata of the secret, so every change results in a new secret.
+func immutableSecretName(sc *v1beta1.SecretClaim, data map[string][]byte) string {
+	h := sha256.New()
+	writeField := func(v []byte) {
+		fmt.Fprintf(h, "%d:", len(v))
+		h.Write(v)
+	}
+	writeMap := func(m map[string][]byte) {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(h, "%d:", len(keys))
+		for _, k := range keys {
+			writeField([]byte(k))
+			writeField(m[k])
+		}
+	}
+	stringMap := func(m map[string]string) map[string][]byte {
+		out := make(map[string][]byte)
+		for k, v := range m {
+			out[k] = []byte(v)
+		}
+		return out
+	}
+	writeField([]byte(secretType(sc)))
+	if tmpl := sc.Spec.SecretTemplate; tmpl != nil {
+		writeMap(stringMap(tmpl.Labels))
+		writeMap(stringMap(tmpl.Annotations))
+	} else {
+		writeMap(nil)
+		writeMap(nil)
+	}
+	writeMap(data)
+	return fmt.Sprintf("%s-%s", secretName(sc), hex.EncodeToString(h.Sum(nil))[:10])
+}
+
+// collectGenerations deletes immutable secrets of a claim which are neither the current one nor one of the
+// revisionHistoryLimit most recent previous generations. If the claim is not immutable anymore, all previous
+// generations are deleted. Generations are selected by the claim UID label of the secrets.
+func (c *controller) collectGenerations(ctx context.Context, sc *v1beta1.SecretClaim, current string) error {
+	limit := 0
+	if sc.Spec.Immutable {
+		limit = int(*sc.Spec.RevisionHistoryLimit)
+	}
+	selector := labels.SelectorFromSet(labels.Set{v1beta1.LabelClaimUID: string(sc.UID)})
+	secrets, err := c.secretLister.Secrets(sc.Namespace).List(selector)
 	if err != nil {
 		return err
 	}
-	if sc.Spec.X509Claim != nil {
-		// TODO(lorenz): Implement reconciliation for X.509
+	var generations []*corev1.Secret
+	for _, secret := range secrets {
+		if secret.Name != current && secret.Immutable != nil && *secret.Immutable && metav1.IsControlledBy(secret, sc) {
+			generations = append(generations, secret)
+		}
+	}
+	if len(generations) <= limit {
 		return nil
 	}
-	newData := make(map[string][]byte)
+	sort.Slice(generations, func(i, j int) bool {
+		ti, tj := generations[i].CreationTimestamp, generations[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return generations[i].Name < generations[j].Name
+	})
+	for _, secret := range generations[limit:] {
+		if err := c.deleteSecret(ctx, sc, secret, "it is an old generation"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretName returns the name of the secret generated for a claim.
+func secretName(sc *v1beta1.SecretClaim) string {
+	if sc.Spec.SecretTemplate != nil && sc.Spec.SecretTemplate.Name != "" {
+		return sc.Spec.SecretTemplate.Name
+	}
+	return sc.Name
+}
+
+// currentSecretName returns the name of the secret most recently generated for a claim.
+func currentSecretName(sc *v1beta1.SecretClaim) string {
+	if sc.Status.SecretName != "" {
+		return sc.Status.SecretName
+	}
+	return secretName(sc)
+}
+
+// secretType returns the type of the secret generated for a claim.
+func secretType(sc *v1beta1.SecretClaim) corev1.SecretType {
+	if sc.Spec.SecretTemplate != nil && sc.Spec.SecretTemplate.Type != "" {
+		return sc.Spec.SecretTemplate.Type
+	}
+	return corev1.SecretTypeOpaque
+}
+
+// secretTypeOf returns the type of an existing secret. The API server defaults an empty type to Opaque.
+func secretTypeOf(secret *corev1.Secret) corev1.SecretType {
+	if secret.Type == "" {
+		return corev1.SecretTypeOpaque
+	}
+	return secret.Type
+}
+
+// deleteSecret deletes a secret controlled by the claim. The UID precondition makes sure that a secret which has been
+// replaced in the meantime is left alone.
+func (c *controller) deleteSecret(ctx context.Context, sc *v1beta1.SecretClaim, secret *corev1.Secret, reason string) error {
+	uid := secret.UID
+	err := c.kclient.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return withReason(reasonSecretDeleteFailed, fmt.Errorf("failed to delete secret \"%s\": %w", secret.Name, err))
+	}
+	c.recorder.Eventf(sc, secret, corev1.EventTypeNormal, "SecretDeleted", "Delete", "Deleted secret \"%s\" as %s", secret.Name, reason)
+	return nil
+}
+
+// deleteForTypeChange deletes a secret whose type differs from the one of the claim so that it can be recreated with
+// the same data, as the type of a secret is immutable. A secret with another name cannot be created first as its name
+// is fixed, so workloads reading the secret in between find it missing. A warning event is emitted beforehand so that
+// such failures can be attributed.
+func (c *controller) deleteForTypeChange(ctx context.Context, sc *v1beta1.SecretClaim, secret *corev1.Secret) error {
+	c.recorder.Eventf(sc, secret, corev1.EventTypeWarning, "SecretRecreating", "Delete",
+		"Deleting secret \"%s/%s\" to recreate it with type %s, it is missing until it has been created again", secret.Namespace, secret.Name, secretType(sc))
+	return c.deleteSecret(ctx, sc, secret, fmt.Sprintf("its type changed from %s to %s", secretTypeOf(secret), secretType(sc)))
+}
+
+// removePreviousSecret cleans up the secret a claim generated before its secret was renamed. Like on deletion of the
+// claim, the secret is kept if the claim has DeletionPolicyRetain.
+func (c *controller) removePreviousSecret(ctx context.Context, sc *v1beta1.SecretClaim, name string) error {
+	secret, err := c.getSecret(ctx, sc.Namespace, name)
+	if err != nil {
+		return err
+	}
+	if secret == nil || !metav1.IsControlledBy(secret, sc) {
+		return nil
+	}
+	if secret.Immutable != nil && *secret.Immutable {
+		if sc.Spec.Immutable {
+			return nil // Old generations are cleaned up by collectGenerations
+		}
+		// The claim is not immutable anymore, so none of its generations is kept.
+		return c.collectGenerations(ctx, sc, secretName(sc))
+	}
+	if sc.Spec.DeletionPolicy == v1beta1.DeletionPolicyRetain {
+		return c.releaseSecret(ctx, sc, secret)
+	}
+	return c.deleteSecret(ctx, sc, secret, fmt.Sprintf("the claim has been changed to generate \"%s\"", secretName(sc)))
+}
+
+// desiredFields returns all fields of the secret of a claim. Fixed and referenced values are copied, values in the
+// existing secret which satisfy the claim are kept and all others are generated. The provisioning URIs and QR codes of
+// OTP fields are derived from their seeds. The keys which have been changed are returned as well.
+func desiredFields(sc *v1beta1.SecretClaim, oldSecret *corev1.Secret, referenced map[string][]byte, wordLists map[string][]string) (map[string][]byte, []string, error) {
+	data := make(map[string][]byte)
+	var changedKeys []string
 	for k, v := range sc.Spec.FixedFields {
+		data[k] = []byte(v)
 		if !bytes.Equal(oldSecret.Data[k], []byte(v)) {
-			newData[k] = []byte(v)
+			changedKeys = append(changedKeys, k)
+		}
+	}
+	for k, v := range referenced {
+		data[k] = v
+		if !bytes.Equal(oldSecret.Data[k], v) {
+			changedKeys = append(changedKeys, k)
 		}
 	}
 	for _, field := range sc.Spec.TokenFields {
-		_, ok := oldSecret.Data[field]
-		if !ok {
-			newToken := make([]byte, tokenLength)
-			if _, err := io.ReadFull(rand.Reader, newToken); err != nil {
-				return fmt.Errorf("failed to read randomness: %w", err)
-			}
-			newData[field] = []byte(hex.EncodeToString(newToken))
+		if v, ok := oldSecret.Data[field]; ok {
+			data[field] = v
+			continue
+		}
+		newToken := make([]byte, tokenLength)
+		if _, err := io.ReadFull(rand.Reader, newToken); err != nil {
+			return nil, nil, fmt.Errorf("failed to read randomness: %w", err)
 		}
+		data[field] = []byte(hex.EncodeToString(newToken))
+		changedKeys = append(changedKeys, field)
 	}
 	for field, spec := range sc.Spec.CustomTokenFields {
-		if !customTokenValid(&spec, string(oldSecret.Data[field])) {
-			token, err := makeCustomToken(&spec)
-			newData[field] = []byte(token)
+		if customTokenValid(&spec, wordLists[field], string(oldSecret.Data[field])) {
+			data[field] = oldSecret.Data[field]
+			continue
+		}
+		token, err := makeCustomToken(&spec, wordLists[field])
+		if err != nil {
+			return nil, nil, withReason(reasonInvalidSpec, fmt.Errorf("invalid custom token field \"%s\": %w", field, err))
+		}
+		data[field] = []byte(token)
+		changedKeys = append(changedKeys, field)
+	}
+	for field, spec := range sc.Spec.OTPFields {
+		seed := string(oldSecret.Data[field])
+		if !otpSeedValid(&spec, seed) {
+			var err error
+			seed, err = makeOTPSeed(&spec)
 			if err != nil {
-				return err
+				return nil, nil, err
+			}
+		}
+		uri := otpauthURI(&spec, seed)
+		derived := map[string][]byte{field: []byte(seed), field + v1beta1.OTPURIKeySuffix: []byte(uri)}
+		if spec.QRCode {
+			png, err := otpQRCode(uri)
+			if err != nil {
+				return nil, nil, withReason(reasonInvalidSpec, fmt.Errorf("failed to encode QR code of OTP field \"%s\": %w", field, err))
+			}
+			derived[field+v1beta1.OTPQRCodeKeySuffix] = png
+		}
+		for k, v := range derived {
+			data[k] = v
+			if !bytes.Equal(oldSecret.Data[k], v) {
+				changedKeys = append(changedKeys, k)
 			}
 		}
 	}
-	if len(newData) > 0 {
-		var patchOps []jsonpatch.JsonPatchOp
-		for k, v := range newData {
-			patchOps = append(patchOps, jsonpatch.JsonPatchOp{
-				Operation: "add",
-				Path:      jsonpatch.PointerFromParts([]string{"data", k}),
-				Value:     base64.StdEncoding.EncodeToString(v),
-			})
+	return data, changedKeys, nil
+}
+
+// reconcileCertificate checks the certificate in an existing secret and reissues it if it is missing, does not match
+// the claim anymore or is due for rotation. It returns the fields of the secret and the newly issued certificate, if
+// any.
+func (c *controller) reconcileCertificate(ctx context.Context, sc *v1beta1.SecretClaim, secret *corev1.Secret, status *v1beta1.SecretClaimStatus) (map[string][]byte, *x509.Certificate, error) {
+	certKey, keyKey := "tls.crt", "tls.key"
+	if sc.Spec.X509Claim.IsCA {
+		certKey, keyKey = "ca.crt", "ca.key"
+	}
+	var caCert *x509.Certificate
+	if name := sc.Spec.X509Claim.CASecretName; name != "" {
+		var err error
+		caCert, _, err = c.certFromSecret(ctx, sc.Namespace, name)
+		if err != nil {
+			err = withReason(reasonCAUnavailable, err)
+			setCondition(sc, status, v1beta1.ConditionIssued, metav1.ConditionFalse, errorReason(err), err.Error())
+			return nil, nil, err
 		}
-		patch, err := json.Marshal(patchOps)
+	}
+	cert, err := parseCertificatePEM(secret.Data[certKey])
+	if err == nil && certificateMatchesClaim(sc, cert, secret.Data[keyKey], caCert) {
+		rotateAt := rotationTime(cert)
+		if rotateAt.IsZero() || time.Now().Before(rotateAt) {
+			c.setCertificateStatus(sc, status, cert, false)
+			return map[string][]byte{certKey: secret.Data[certKey], keyKey: secret.Data[keyKey]}, nil, nil
+		}
+		setCondition(sc, status, v1beta1.ConditionRotationDue, metav1.ConditionTrue, reasonNearingExpiry,
+			fmt.Sprintf("Certificate expires at %v", cert.NotAfter.Format(time.RFC3339)))
+	}
+	newData := make(map[string][]byte)
+	cert, err = c.issueCertificate(ctx, sc, newData)
+	if err != nil {
+		setCondition(sc, status, v1beta1.ConditionIssued, metav1.ConditionFalse, errorReason(err), err.Error())
+		return nil, nil, fmt.Errorf("failed to issue certificate: %w", err)
+	}
+	return newData, cert, nil
+}
+
+// setCertificateStatus records a valid certificate in status and schedules the claim to be reconciled again once the
+// certificate is due for rotation.
+func (c *controller) setCertificateStatus(sc *v1beta1.SecretClaim, status *v1beta1.SecretClaimStatus, cert *x509.Certificate, issued bool) {
+	status.Certificate = certificateStatus(cert)
+	if issued {
+		certificatesIssuedTotal.Inc()
+		c.recorder.Eventf(sc, nil, corev1.EventTypeNormal, "CertificateIssued", "Issue", "Issued certificate with serial number %s valid until %v",
+			status.Certificate.SerialNumber, cert.NotAfter.Format(time.RFC3339))
+	}
+	if issued || status.LastRotationTime == nil {
+		rotatedAt := metav1.NewTime(cert.NotBefore)
+		status.LastRotationTime = &rotatedAt
+	}
+	setCondition(sc, status, v1beta1.ConditionIssued, metav1.ConditionTrue, reasonCertificateIssued,
+		fmt.Sprintf("Certificate with serial number %s is valid until %v", status.Certificate.SerialNumber, cert.NotAfter.Format(time.RFC3339)))
+	rotateAt := rotationTime(cert)
+	if rotateAt.IsZero() {
+		setCondition(sc, status, v1beta1.ConditionRotationDue, metav1.ConditionFalse, reasonNoExpiry, "Certificate never expires")
+		return
+	}
+	setCondition(sc, status, v1beta1.ConditionRotationDue, metav1.ConditionFalse, reasonWithinValidity,
+		fmt.Sprintf("Certificate will be rotated after %v", rotateAt.Format(time.RFC3339)))
+	c.enqueueSCAfter(sc, time.Until(rotateAt))
+}
+
+// checkAdoption checks if the claim may become the controller of an existing secret which it does not control yet.
+// This is only allowed if the claim explicitly asks for it and the secret does not have another controller.
+func checkAdoption(sc *v1beta1.SecretClaim, secret *corev1.Secret) error {
+	if owner := metav1.GetControllerOf(secret); owner != nil {
+		return withReason(reasonSecretConflict, fmt.Errorf("secret \"%s\" is controlled by %s \"%s\"", secret.Name, owner.Kind, owner.Name))
+	}
+	if sc.Spec.AdoptionPolicy != v1beta1.AdoptionPolicyAdopt {
+		return withReason(reasonSecretConflict, fmt.Errorf("secret \"%s\" already exists and is not owned by this claim, set adoptionPolicy to Adopt to take it over", secret.Name))
+	}
+	if secretTypeOf(secret) != secretType(sc) {
+		return withReason(reasonSecretConflict, fmt.Errorf("secret \"%s\" has type %s, but the claim requires %s", secret.Name, secretTypeOf(secret), secretType(sc)))
+	}
+	return nil
+}
+
+// secretApplyConfiguration returns the fields of the secret of a claim which are owned by the controller.
+func secretApplyConfiguration(sc *v1beta1.SecretClaim, name string, data map[string][]byte) *corev1ac.SecretApplyConfiguration {
+	secret := corev1ac.Secret(name, sc.Namespace).WithType(secretType(sc))
+	if sc.Spec.Immutable {
+		secret.WithImmutable(true)
+	}
+	if tmpl := sc.Spec.SecretTemplate; tmpl != nil {
+		secret.WithLabels(tmpl.Labels).WithAnnotations(tmpl.Annotations)
+	}
+	return secret.
+		WithLabels(map[string]string{v1beta1.LabelClaimUID: string(sc.UID)}).
+		WithOwnerReferences(metav1ac.OwnerReference().
+			WithAPIVersion(v1beta1.SchemeGroupVersion.String()).
+			WithKind(v1beta1.Kind).
+			WithName(sc.Name).
+			WithUID(sc.UID).
+			WithController(true).
+			WithBlockOwnerDeletion(true)).
+		WithData(data)
+}
+
+// applySecret writes the secret of a claim using server-side apply. The API server tracks which fields are owned by
+// the controller, fields which were applied before but are no longer part of data are removed unless another field
+// manager owns them as well. Fields which are owned by other managers and have different values are not overwritten,
+// the claim reports a FieldConflict instead. oldSecret is nil if the secret does not exist yet.
+func (c *controller) applySecret(ctx context.Context, sc *v1beta1.SecretClaim, name string, oldSecret *corev1.Secret, data map[string][]byte, adopting bool, changedKeys []string) error {
+	desired := secretApplyConfiguration(sc, name, data)
+	var removedKeys []string
+	if oldSecret != nil {
+		if err := c.upgradeManagedFields(ctx, oldSecret); err != nil {
+			return err
+		}
+		applied, err := corev1ac.ExtractSecret(oldSecret, fieldManager)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("failed to extract applied fields of secret: %w", err)
 		}
-		if _, err := c.kclient.CoreV1().Secrets(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager}); err != nil {
-			return fmt.Errorf("failed to patch secret: %w", err)
+		if equality.Semantic.DeepEqual(applied, desired) {
+			return nil
+		}
+		for k := range applied.Data {
+			if _, ok := data[k]; !ok {
+				removedKeys = append(removedKeys, k)
+			}
 		}
 	}
+	appliedSecret, err := c.kclient.CoreV1().Secrets(sc.Namespace).Apply(ctx, desired, metav1.ApplyOptions{FieldManager: fieldManager})
+	if apierrors.IsConflict(err) {
+		return withReason(reasonFieldConflict, fmt.Errorf("fields of secret \"%s\" are managed by others: %w", name, err))
+	}
+	if err != nil {
+		if oldSecret == nil {
+			return withReason(reasonSecretCreateFailed, fmt.Errorf("failed to create new secret: %w", err))
+		}
+		return withReason(reasonSecretPatchFailed, fmt.Errorf("failed to apply secret: %w", err))
+	}
+	if oldSecret == nil {
+		secretsCreatedTotal.Inc()
+		c.recorder.Eventf(sc, appliedSecret, corev1.EventTypeNormal, "SecretCreated", "Create", "Created secret \"%s\"", name)
+		return nil
+	}
+	secretsPatchedTotal.Inc()
+	if adopting {
+		c.recorder.Eventf(sc, appliedSecret, corev1.EventTypeNormal, "SecretAdopted", "Adopt", "Adopted existing secret \"%s\"", name)
+	}
+	if len(changedKeys) > 0 {
+		sort.Strings(changedKeys)
+		c.recorder.Eventf(sc, appliedSecret, corev1.EventTypeNormal, "SecretPatched", "Apply", "Updated keys %s in secret \"%s\"", strings.Join(changedKeys, ", "), name)
+	}
+	if len(removedKeys) > 0 {
+		sort.Strings(removedKeys)
+		c.recorder.Eventf(sc, appliedSecret, corev1.EventTypeNormal, "SecretPruned", "Apply", "Removed keys %s no longer declared by the claim from secret \"%s\"", strings.Join(removedKeys, ", "), name)
+	}
+	return nil
+}
+
+// upgradeManagedFields transfers ownership of fields written by older versions of the controller with create and
+// patch requests to its apply field manager. Without this, keys which are no longer declared by a claim would never
+// be pruned from secrets created by older versions.
+func (c *controller) upgradeManagedFields(ctx context.Context, secret *corev1.Secret) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(secret, sets.New(fieldManager), fieldManager)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade managed fields of secret: %w", err)
+	}
+	if patch == nil {
+		return nil
+	}
+	if _, err := c.kclient.CoreV1().Secrets(secret.Namespace).Patch(ctx, secret.Name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return withReason(reasonSecretPatchFailed, fmt.Errorf("failed to upgrade managed fields of secret: %w", err))
+	}
 	return nil
 }
 
@@ -489,12 +1223,49 @@ func main() {
 		klog.Fatalf("Error building example clientset: %s", err.Error())
 	}
 
+	metadataClient, err := metadata.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("Error building metadata client: %s", err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	utilruntime.Must(dsscheme.AddToScheme(scheme.Scheme))
+	eventBroadcaster := events.NewBroadcaster(&events.EventSinkImpl{Interface: kubeClient.EventsV1()})
+	eventBroadcaster.StartRecordingToSink(ctx.Done())
+	defer eventBroadcaster.Shutdown()
+
 	dsInformerFactory := informers.NewSharedInformerFactory(dsClient, time.Minute*5)
 	scClient := dsInformerFactory.Dolansoft().V1beta1().SecretClaims()
+	policyClient := dsInformerFactory.Dolansoft().V1beta1().SecretClaimPolicies()
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Minute*5)
+	// Only secrets written by the controller are cached in full, other secrets can be large and numerous
+	secretInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, time.Minute*5,
+		kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = v1beta1.LabelClaimUID
+		}))
+	secretClient := secretInformerFactory.Core().V1().Secrets()
+	metadataInformerFactory := metadatainformer.NewSharedInformerFactory(metadataClient, time.Minute*5)
+	secretMetadataClient := metadataInformerFactory.ForResource(corev1.SchemeGroupVersion.WithResource("secrets"))
+	namespaceClient := kubeInformerFactory.Core().V1().Namespaces()
+	configMapClient := kubeInformerFactory.Core().V1().ConfigMaps()
+	if err := scClient.Informer().AddIndexers(cache.Indexers{referencedSourcesIndex: indexReferencedSources}); err != nil {
+		klog.Fatalf("Error adding indexer: %s", err.Error())
+	}
 	ctrl := controller{
-		kclient:  kubeClient,
-		dsclient: dsClient,
-		queue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		kclient:              kubeClient,
+		dsclient:             dsClient,
+		scLister:             scClient.Lister(),
+		scIndexer:            scClient.Informer().GetIndexer(),
+		configMapLister:      configMapClient.Lister(),
+		secretLister:         secretClient.Lister(),
+		secretMetadataLister: secretMetadataClient.Lister(),
+		namespaceLister:      namespaceClient.Lister(),
+		policyLister:         policyClient.Lister(),
+		minTokenEntropyBits:  int32(*minTokenEntropyBits),
+		queue:                workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "secretclaims"),
+		recorder:             eventBroadcaster.NewRecorder(scheme.Scheme, fieldManager),
 	}
 	scClient.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
@@ -507,8 +1278,206 @@ func main() {
 			// K8s GC automatically cleans up after us
 		},
 	})
-	go ctrl.processQueueItems(ctrl.queue, func(key string) error {
-		return ctrl.reconcileSC(key)
+	secretClient.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				return false
+			}
+			_, replica := replicaSource(secret)
+			return replica || secretClaimOwner(secret) != nil || len(ctrl.referencingSCs(secretSourceKey(secret.Namespace, secret.Name))) > 0
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				ctrl.enqueueOwnerSC(obj)
+				ctrl.enqueueReferencingSCs(obj)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				if oldObj.(*corev1.Secret).ResourceVersion == newObj.(*corev1.Secret).ResourceVersion {
+					return // Periodic resync, the claim itself is resynced as well
+				}
+				ctrl.enqueueOwnerSC(newObj)
+				ctrl.enqueueReferencingSCs(newObj)
+			},
+			DeleteFunc: func(obj interface{}) {
+				ctrl.enqueueOwnerSC(obj)
+				ctrl.enqueueReferencingSCs(obj)
+			},
+		},
+	})
+	// Secrets without LabelClaimUID are CA secrets, referenced secrets or secrets written by older versions of the
+	// controller. Only their metadata is watched, getSecret fetches them when needed.
+	secretMetadataClient.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			secret := secretFromMetadata(obj)
+			if secret == nil {
+				return false
+			}
+			_, labeled := secret.Labels[v1beta1.LabelClaimUID]
+			return !labeled
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				secret := secretFromMetadata(obj)
+				ctrl.enqueueOwnerSC(secret)
+				ctrl.enqueueReferencingSCs(secret)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldSecret, newSecret := secretFromMetadata(oldObj), secretFromMetadata(newObj)
+				if oldSecret.ResourceVersion == newSecret.ResourceVersion {
+					return // Periodic resync, the claim itself is resynced as well
+				}
+				ctrl.enqueueOwnerSC(newSecret)
+				ctrl.enqueueReferencingSCs(newSecret)
+			},
+			DeleteFunc: func(obj interface{}) {
+				secret := secretFromMetadata(obj)
+				ctrl.unmanagedSecrets.Delete(secret.Namespace + "/" + secret.Name)
+				ctrl.enqueueOwnerSC(secret)
+				ctrl.enqueueReferencingSCs(secret)
+			},
+		},
+	})
+	configMapClient.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ctrl.enqueueReferencingSCs(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if oldObj.(*corev1.ConfigMap).ResourceVersion == newObj.(*corev1.ConfigMap).ResourceVersion {
+				return // Periodic resync, the claim itself is resynced as well
+			}
+			ctrl.enqueueReferencingSCs(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			ctrl.enqueueReferencingSCs(obj)
+		},
+	})
+	namespaceClient.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ctrl.enqueueReplicatingSCs(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNS, newNS := oldObj.(*corev1.Namespace), newObj.(*corev1.Namespace)
+			if equality.Semantic.DeepEqual(oldNS.Labels, newNS.Labels) && equality.Semantic.DeepEqual(oldNS.Annotations, newNS.Annotations) {
+				return // Only labels and annotations affect replication and policies
+			}
+			ctrl.enqueueReplicatingSCs(newObj)
+			if !equality.Semantic.DeepEqual(oldNS.Labels, newNS.Labels) {
+				ctrl.enqueueNamespaceSCs(newNS)
+			}
+		},
+	})
+	policyClient.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ctrl.enqueueAllSCs()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if oldObj.(*v1beta1.SecretClaimPolicy).ResourceVersion == newObj.(*v1beta1.SecretClaimPolicy).ResourceVersion {
+				return // Periodic resync, the claims are resynced as well
+			}
+			ctrl.enqueueAllSCs()
+		},
+		DeleteFunc: func(obj interface{}) {
+			ctrl.enqueueAllSCs()
+		},
+	})
+	cacheSynced := func() bool {
+		return scClient.Informer().HasSynced() && secretClient.Informer().HasSynced() &&
+			secretMetadataClient.Informer().HasSynced() &&
+			namespaceClient.Informer().HasSynced() && configMapClient.Informer().HasSynced() &&
+			policyClient.Informer().HasSynced()
+	}
+	prometheus.MustRegister(certificateExpiryCollector{lister: scClient.Lister()})
+	health := newHealthChecks(cacheSynced)
+	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/healthz", health.healthz)
+	http.HandleFunc("/readyz", health.readyz)
+	go func() {
+		klog.Fatal(http.ListenAndServe(*httpAddress, nil))
+	}()
+	if *webhookAddress != "" {
+		// The webhook is served by all replicas, not just the leader
+		webhookMux := http.NewServeMux()
+		webhookMux.Handle("/validate-secretclaim", &validatingWebhook{
+			kclient:             kubeClient,
+			dsclient:            dsClient,
+			minTokenEntropyBits: int32(*minTokenEntropyBits),
+		})
+		webhookMux.HandleFunc("/convert", serveConvertSecretClaim)
+		go func() {
+			klog.Fatal(http.ListenAndServeTLS(*webhookAddress, *webhookCertFile, *webhookKeyFile, webhookMux))
+		}()
+	}
+
+	run := func(ctx context.Context) {
+		health.setLeading(true)
+		dsInformerFactory.Start(ctx.Done())
+		kubeInformerFactory.Start(ctx.Done())
+		secretInformerFactory.Start(ctx.Done())
+		metadataInformerFactory.Start(ctx.Done())
+		if !cache.WaitForCacheSync(ctx.Done(), scClient.Informer().HasSynced, secretClient.Informer().HasSynced,
+			secretMetadataClient.Informer().HasSynced,
+			namespaceClient.Informer().HasSynced, configMapClient.Informer().HasSynced, policyClient.Informer().HasSynced) {
+			log.Printf("Shut down before caches were synced")
+			return
+		}
+		ctrl.run(ctx, *workers, *shutdownTimeout)
+	}
+
+	if !*leaderElect {
+		run(ctx)
+		return
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("Error getting hostname: %s", err.Error())
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      *leaseName,
+			Namespace: *leaseNamespace,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: hostname + "_" + uuid.New().String(),
+		},
+	}
+	// The lease is only released once all workers have finished so that the next leader never runs concurrently with
+	// in-flight reconciles of this replica.
+	leCtx, leCancel := context.WithCancel(context.Background())
+	defer leCancel()
+	go func() {
+		<-ctx.Done()
+		if !health.isLeading() {
+			leCancel()
+		}
+	}()
+	leaderelection.RunOrDie(leCtx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   *leaseDuration,
+		RenewDeadline:   *leaseRenewDeadline,
+		RetryPeriod:     *leaseRetryPeriod,
+		ReleaseOnCancel: true,
+		WatchDog:        health.leaderElection,
+		Name:            *leaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				defer leCancel()
+				run(ctx)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() == nil {
+					klog.Fatalf("Lost leadership, exiting")
+				}
+				log.Printf("Released leadership")
+			},
+			OnNewLeader: func(identity string) {
+				log.Printf("Current leader is %s", identity)
+			},
+		},
 	})
-	scClient.Informer().Run(make(<-chan struct{}))
 }
@@ -0,0 +1,1448 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	dsfake "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/fake"
+	listers "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/listers/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/endpoints/handlers/fieldmanager"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/structured-merge-diff/v4/typed"
+)
+
+// testController is a controller backed by fake clientsets. Instead of running informers, its caches are filled
+// explicitly from the fake clientsets by syncCaches.
+type testController struct {
+	*controller
+	kclient   *kubefake.Clientset
+	dsclient  *dsfake.Clientset
+	scIndexer cache.Indexer
+	// secretIndexer only contains secrets labeled with LabelClaimUID like the filtered informer, secretMetadataIndexer
+	// contains all secrets.
+	secretIndexer         cache.Indexer
+	secretMetadataIndexer cache.Indexer
+	namespaceIndexer      cache.Indexer
+	configMapIndexer      cache.Indexer
+	policyIndexer         cache.Indexer
+	// secretVersions emulates the resourceVersion of secrets, which the object tracker of the fake clientset does not
+	// maintain. It maps namespace/name to the last synced content and its resourceVersion.
+	secretVersions map[string]secretVersion
+	lastVersion    int
+}
+
+type secretVersion struct {
+	content         string
+	resourceVersion string
+}
+
+func newTestController(t testing.TB, objects ...runtime.Object) *testController {
+	var kubeObjects, dsObjects []runtime.Object
+	for _, obj := range objects {
+		switch obj.(type) {
+		case *v1beta1.SecretClaim, *v1beta1.SecretClaimPolicy:
+			dsObjects = append(dsObjects, obj)
+		default:
+			kubeObjects = append(kubeObjects, obj)
+		}
+	}
+	tc := &testController{
+		kclient:  kubefake.NewSimpleClientset(kubeObjects...),
+		dsclient: dsfake.NewSimpleClientset(dsObjects...),
+		scIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			cache.NamespaceIndex:   cache.MetaNamespaceIndexFunc,
+			referencedSourcesIndex: indexReferencedSources,
+		}),
+		secretIndexer:         cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		secretMetadataIndexer: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		namespaceIndexer:      cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		configMapIndexer:      cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		policyIndexer:         cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}),
+		secretVersions:        make(map[string]secretVersion),
+	}
+	tc.controller = &controller{
+		kclient:              tc.kclient,
+		dsclient:             tc.dsclient,
+		scLister:             listers.NewSecretClaimLister(tc.scIndexer),
+		scIndexer:            tc.scIndexer,
+		secretLister:         corelisters.NewSecretLister(tc.secretIndexer),
+		secretMetadataLister: cache.NewGenericLister(tc.secretMetadataIndexer, corev1.Resource("secrets")),
+		namespaceLister:      corelisters.NewNamespaceLister(tc.namespaceIndexer),
+		configMapLister:      corelisters.NewConfigMapLister(tc.configMapIndexer),
+		policyLister:         listers.NewSecretClaimPolicyLister(tc.policyIndexer),
+		queue:                workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		recorder:             &events.FakeRecorder{},
+	}
+	tc.kclient.PrependReactor("patch", "secrets", applyReactor(t, tc.kclient.Tracker()))
+	t.Cleanup(tc.queue.ShutDown)
+	tc.syncCaches(t)
+	return tc
+}
+
+// secretTypeConverter converts secrets to typed values using the schema the applyconfigurations of client-go use.
+type secretTypeConverter struct {
+	secretType typed.ParseableType
+}
+
+func (c secretTypeConverter) ObjectToTyped(obj runtime.Object) (*typed.TypedValue, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return c.secretType.FromUnstructured(u.Object)
+	}
+	return c.secretType.FromStructured(obj)
+}
+
+func (c secretTypeConverter) TypedToObject(value *typed.TypedValue) (runtime.Object, error) {
+	content, ok := value.AsValue().Unstructured().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected object, got %T", value.AsValue().Unstructured())
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+// secretFieldManager returns the field manager of the API server for secrets, which tracks managedFields and merges
+// apply requests.
+func secretFieldManager(t testing.TB) *fieldmanager.FieldManager {
+	schemaYAML, err := os.ReadFile("testdata/secret-schema.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser, err := typed.NewParser(typed.YAMLObject(schemaYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeConverter := secretTypeConverter{secretType: parser.Type("io.k8s.api.core.v1.Secret")}
+	fm, err := fieldmanager.NewDefaultFieldManager(typeConverter, scheme.Scheme, scheme.Scheme, scheme.Scheme,
+		corev1.SchemeGroupVersion.WithKind("Secret"), corev1.SchemeGroupVersion, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fm
+}
+
+// applyReactor serves server-side apply requests for secrets with the field manager of the API server, as the object
+// tracker of the fake clientset handles apply patches like strategic merge patches. The fake clientset does not pass
+// on apply options, so all requests are attributed to the field manager of the controller without forcing.
+func applyReactor(t testing.TB, tracker k8stesting.ObjectTracker) k8stesting.ReactionFunc {
+	fm := secretFieldManager(t)
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(k8stesting.PatchAction)
+		if !ok || patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		applied := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patchAction.GetPatch(), &applied.Object); err != nil {
+			return true, nil, apierrors.NewBadRequest(err.Error())
+		}
+		gvr, namespace, name := action.GetResource(), action.GetNamespace(), patchAction.GetName()
+		live, err := tracker.Get(gvr, namespace, name)
+		exists := err == nil
+		if apierrors.IsNotFound(err) {
+			live = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		} else if err != nil {
+			return true, nil, err
+		}
+		merged, err := fm.Apply(live, applied, fieldManager, false)
+		if err != nil {
+			return true, nil, err
+		}
+		secret, err := toSecret(merged)
+		if err != nil {
+			return true, nil, err
+		}
+		if exists {
+			err = tracker.Update(gvr, secret, namespace)
+		} else {
+			err = tracker.Create(gvr, secret, namespace)
+		}
+		return true, secret, err
+	}
+}
+
+func toSecret(obj runtime.Object) (*corev1.Secret, error) {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		return secret, nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// syncCaches replaces the contents of the caches with the current state of the fake clientsets, like informers would
+// eventually do.
+func (tc *testController) syncCaches(t testing.TB) {
+	ctx := context.Background()
+	claims, err := tc.dsclient.DolansoftV1beta1().SecretClaims("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scItems []interface{}
+	for i := range claims.Items {
+		scItems = append(scItems, &claims.Items[i])
+	}
+	if err := tc.scIndexer.Replace(scItems, ""); err != nil {
+		t.Fatal(err)
+	}
+	secrets, err := tc.kclient.CoreV1().Secrets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var secretItems, secretMetadataItems []interface{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		tc.setSecretVersion(t, secret)
+		if _, ok := secret.Labels[v1beta1.LabelClaimUID]; ok {
+			secretItems = append(secretItems, secret)
+		}
+		secretMetadataItems = append(secretMetadataItems, &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: secret.ObjectMeta,
+		})
+	}
+	if err := tc.secretIndexer.Replace(secretItems, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := tc.secretMetadataIndexer.Replace(secretMetadataItems, ""); err != nil {
+		t.Fatal(err)
+	}
+	namespaces, err := tc.kclient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var namespaceItems []interface{}
+	for i := range namespaces.Items {
+		namespaceItems = append(namespaceItems, &namespaces.Items[i])
+	}
+	if err := tc.namespaceIndexer.Replace(namespaceItems, ""); err != nil {
+		t.Fatal(err)
+	}
+	configMaps, err := tc.kclient.CoreV1().ConfigMaps("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var configMapItems []interface{}
+	for i := range configMaps.Items {
+		configMapItems = append(configMapItems, &configMaps.Items[i])
+	}
+	if err := tc.configMapIndexer.Replace(configMapItems, ""); err != nil {
+		t.Fatal(err)
+	}
+	policies, err := tc.dsclient.DolansoftV1beta1().SecretClaimPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var policyItems []interface{}
+	for i := range policies.Items {
+		policyItems = append(policyItems, &policies.Items[i])
+	}
+	if err := tc.policyIndexer.Replace(policyItems, ""); err != nil {
+		t.Fatal(err)
+	}
+	tc.kclient.ClearActions()
+	tc.dsclient.ClearActions()
+}
+
+// setSecretVersion updates the resourceVersion of a secret in the object tracker if its content changed since the last
+// sync.
+func (tc *testController) setSecretVersion(t testing.TB, secret *corev1.Secret) {
+	key := secret.Namespace + "/" + secret.Name
+	withoutVersion := secret.DeepCopy()
+	withoutVersion.ResourceVersion = ""
+	content, err := json.Marshal(withoutVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, ok := tc.secretVersions[key]; ok && version.content == string(content) {
+		secret.ResourceVersion = version.resourceVersion
+		return
+	}
+	tc.lastVersion++
+	secret.ResourceVersion = fmt.Sprint(tc.lastVersion)
+	tc.secretVersions[key] = secretVersion{content: string(content), resourceVersion: secret.ResourceVersion}
+	if err := tc.kclient.Tracker().Update(corev1.SchemeGroupVersion.WithResource("secrets"), secret, secret.Namespace); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// resync reconciles all claims once, like after a periodic informer resync, and returns the number of API calls made.
+func (tc *testController) resync(t testing.TB) int {
+	for _, key := range tc.scIndexer.ListKeys() {
+		if err := tc.reconcileSC(context.Background(), key); err != nil {
+			t.Fatalf("failed to reconcile %v: %v", key, err)
+		}
+	}
+	calls := len(tc.kclient.Actions()) + len(tc.dsclient.Actions())
+	tc.kclient.ClearActions()
+	tc.dsclient.ClearActions()
+	return calls
+}
+
+func testClaims(n int) []runtime.Object {
+	var objects []runtime.Object
+	for i := 0; i < n; i++ {
+		objects = append(objects, &v1beta1.SecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("token-%d", i), Namespace: "default"},
+			Spec: v1beta1.SecretClaimSpec{
+				TokenFields: []string{"token"},
+				FixedFields: map[string]string{"user": "test"},
+				CustomTokenFields: map[string]v1beta1.CustomTokenSpec{
+					"password": {Length: 32, Encoding: "base64url"},
+				},
+			},
+		})
+		objects = append(objects, &v1beta1.SecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cert-%d", i), Namespace: "default"},
+			Spec: v1beta1.SecretClaimSpec{
+				X509Claim: &v1beta1.X509Claim{RotateEvery: "30d", ServiceNames: []string{"test"}},
+			},
+		})
+	}
+	return objects
+}
+
+func TestResyncWithoutChangesMakesNoAPICalls(t *testing.T) {
+	tc := newTestController(t, testClaims(2)...)
+	if calls := tc.resync(t); calls == 0 {
+		t.Fatalf("expected API calls to create secrets and statuses")
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected no API calls when everything is up to date, got %d", calls)
+	}
+}
+
+func benchmarkResync(b *testing.B, claims int) {
+	tc := newTestController(b, testClaims(claims/2)...)
+	tc.resync(b)
+	tc.syncCaches(b)
+	b.ResetTimer()
+	var calls int
+	for i := 0; i < b.N; i++ {
+		calls += tc.resync(b)
+	}
+	b.ReportMetric(float64(calls)/float64(b.N), "apicalls/resync")
+}
+
+func BenchmarkResync100(b *testing.B) {
+	benchmarkResync(b, 100)
+}
+
+func BenchmarkResync1000(b *testing.B) {
+	benchmarkResync(b, 1000)
+}
+
+func TestAdoption(t *testing.T) {
+	foreignSecret := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopt", Namespace: "default"},
+			Data:       map[string][]byte{"token": []byte("existing"), "extra": []byte("kept")},
+		}
+	}
+	tests := []struct {
+		name      string
+		policy    v1beta1.AdoptionPolicy
+		wantOwned bool
+	}{
+		{"Refuses by default", "", false},
+		{"Refuses explicitly", v1beta1.AdoptionPolicyRefuse, false},
+		{"Adopts", v1beta1.AdoptionPolicyAdopt, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &v1beta1.SecretClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "adopt", Namespace: "default", UID: "claim-uid"},
+				Spec: v1beta1.SecretClaimSpec{
+					TokenFields:    []string{"token", "missing"},
+					AdoptionPolicy: tt.policy,
+				},
+			}
+			tc := newTestController(t, sc, foreignSecret())
+			err := tc.reconcileSC(context.Background(), "default/adopt")
+			secret, getErr := tc.kclient.CoreV1().Secrets("default").Get(context.Background(), "adopt", metav1.GetOptions{})
+			if getErr != nil {
+				t.Fatal(getErr)
+			}
+			if owned := metav1.IsControlledBy(secret, sc); owned != tt.wantOwned {
+				t.Fatalf("secret controlled by claim = %v, want %v (reconcile error: %v)", owned, tt.wantOwned, err)
+			}
+			if !tt.wantOwned {
+				if errorReason(err) != reasonSecretConflict {
+					t.Errorf("expected conflict, got %v", err)
+				}
+				if len(secret.Data) != 2 {
+					t.Errorf("foreign secret was modified: %v", secret.Data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to reconcile: %v", err)
+			}
+			if string(secret.Data["token"]) != "existing" || string(secret.Data["extra"]) != "kept" {
+				t.Errorf("existing values were not kept: %v", secret.Data)
+			}
+			if len(secret.Data["missing"]) != 2*tokenLength {
+				t.Errorf("missing token was not generated: %v", secret.Data)
+			}
+		})
+	}
+}
+
+func TestRetainOnDeletion(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "retain", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields:    []string{"password"},
+			DeletionPolicy: v1beta1.DeletionPolicyRetain,
+		},
+	}
+	tc := newTestController(t, sc)
+	if err := tc.reconcileSC(ctx, "default/retain"); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "retain", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasFinalizer(sc, v1beta1.FinalizerRetainSecret) {
+		t.Fatalf("expected finalizer on claim with Retain policy, got %v", sc.Finalizers)
+	}
+
+	now := metav1.Now()
+	sc.DeletionTimestamp = &now
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/retain"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "retain", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret.OwnerReferences) != 0 {
+		t.Errorf("expected owner reference to be removed, got %v", secret.OwnerReferences)
+	}
+	if secret.Labels[v1beta1.LabelOrphaned] != "true" {
+		t.Errorf("expected secret to be labeled as orphaned, got %v", secret.Labels)
+	}
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "retain", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sc.Finalizers) != 0 {
+		t.Errorf("expected finalizer to be removed, got %v", sc.Finalizers)
+	}
+}
+
+func TestRetainOnForegroundDeletion(t *testing.T) {
+	ctx := context.Background()
+	for _, secretDeleted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("secretDeleted=%v", secretDeleted), func(t *testing.T) {
+			now := metav1.Now()
+			sc := &v1beta1.SecretClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "retain", Namespace: "default", UID: "claim-uid", DeletionTimestamp: &now,
+					Finalizers: []string{metav1.FinalizerDeleteDependents, v1beta1.FinalizerRetainSecret}},
+				Spec: v1beta1.SecretClaimSpec{
+					TokenFields:    []string{"password"},
+					DeletionPolicy: v1beta1.DeletionPolicyRetain,
+				},
+			}
+			objects := []runtime.Object{sc}
+			if !secretDeleted {
+				objects = append(objects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "retain", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(sc, v1beta1.SchemeGroupVersion.WithKind(v1beta1.Kind)),
+					}},
+				})
+			}
+			tc := newTestController(t, objects...)
+			recorder := events.NewFakeRecorder(10)
+			tc.recorder = recorder
+			if err := tc.reconcileSC(ctx, "default/retain"); err != nil {
+				t.Fatal(err)
+			}
+			sc, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "retain", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sc.Finalizers, []string{metav1.FinalizerDeleteDependents}) {
+				t.Errorf("expected only the finalizer of the garbage collector to be left, got %v", sc.Finalizers)
+			}
+			close(recorder.Events)
+			var warned bool
+			for event := range recorder.Events {
+				warned = warned || strings.HasPrefix(event, "Warning SecretNotRetained")
+			}
+			if warned != secretDeleted {
+				t.Errorf("expected warning about the lost secret = %v, got %v", secretDeleted, warned)
+			}
+			if secretDeleted {
+				return
+			}
+			secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "retain", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(secret.OwnerReferences) != 0 || secret.Labels[v1beta1.LabelOrphaned] != "true" {
+				t.Errorf("expected secret to be released, got %v", secret.ObjectMeta)
+			}
+		})
+	}
+}
+
+func TestRetainReplicasOnDeletion(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "retain", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields:    []string{"password"},
+			DeletionPolicy: v1beta1.DeletionPolicyRetain,
+			Replication:    &v1beta1.Replication{Namespaces: []string{"app"}},
+		},
+	}
+	tc := newTestController(t, sc,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Annotations: map[string]string{v1beta1.AnnotationAllowReplicationFrom: "*"}}},
+	)
+	if err := tc.reconcileSC(ctx, "default/retain"); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "retain", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := metav1.Now()
+	sc.DeletionTimestamp = &now
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/retain"); err != nil {
+		t.Fatal(err)
+	}
+	for _, namespace := range []string{"default", "app"} {
+		secret, err := tc.kclient.CoreV1().Secrets(namespace).Get(ctx, "retain", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected secret in namespace %q to be retained: %v", namespace, err)
+		}
+		if len(secret.OwnerReferences) != 0 {
+			t.Errorf("expected owner reference of secret in namespace %q to be removed, got %v", namespace, secret.OwnerReferences)
+		}
+		if secret.Labels[v1beta1.LabelOrphaned] != "true" {
+			t.Errorf("expected secret in namespace %q to be labeled as orphaned, got %v", namespace, secret.Labels)
+		}
+		if _, ok := secret.Labels[v1beta1.LabelReplicaOf]; ok {
+			t.Errorf("expected replica label of secret in namespace %q to be removed, got %v", namespace, secret.Labels)
+		}
+	}
+}
+
+func TestCertificateRepair(t *testing.T) {
+	ctx := context.Background()
+	tc := newTestController(t,
+		&v1beta1.SecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default", UID: "ca-uid"},
+			Spec:       v1beta1.SecretClaimSpec{X509Claim: &v1beta1.X509Claim{IsCA: true, CommonName: "Test CA"}},
+		},
+		&v1beta1.SecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "leaf", Namespace: "default", UID: "leaf-uid"},
+			Spec:       v1beta1.SecretClaimSpec{X509Claim: &v1beta1.X509Claim{CASecretName: "ca", ServiceNames: []string{"leaf"}}},
+		},
+	)
+	// The leaf can only be issued once the CA secret is in the cache.
+	reconcileInOrder := func() {
+		for _, key := range []string{"default/ca", "default/leaf"} {
+			if err := tc.reconcileSC(ctx, key); err != nil {
+				t.Fatalf("failed to reconcile %v: %v", key, err)
+			}
+			tc.syncCaches(t)
+		}
+	}
+	reconcileInOrder()
+	if calls := tc.resync(t); calls != 0 {
+		t.Fatalf("expected no API calls for issued certificates, got %d", calls)
+	}
+	getLeaf := func() *corev1.Secret {
+		secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "leaf", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+	leaf := getLeaf()
+
+	delete(leaf.Data, "tls.key")
+	if _, err := tc.kclient.CoreV1().Secrets("default").Update(ctx, leaf, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	reconcileInOrder()
+	repaired := getLeaf()
+	if len(repaired.Data["tls.key"]) == 0 || bytes.Equal(repaired.Data["tls.crt"], leaf.Data["tls.crt"]) {
+		t.Fatalf("expected certificate with a missing key to be reissued, got %v", repaired.Data)
+	}
+
+	ca, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "ca", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.Spec.X509Claim.CommonName = "New Test CA"
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, ca, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	reconcileInOrder()
+	reissued := getLeaf()
+	if bytes.Equal(reissued.Data["tls.crt"], repaired.Data["tls.crt"]) {
+		t.Fatal("expected certificate to be reissued after its CA was reissued")
+	}
+	caSecret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "ca", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := parseCertificatePEM(caSecret.Data["ca.crt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := parseCertificatePEM(reissued.Data["tls.crt"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("expected reissued certificate to chain to the new CA: %v", err)
+	}
+}
+
+func TestPruneRemovedKeys(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "prune", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token", "old"},
+			FixedFields: map[string]string{"user": "test"},
+		},
+	}
+	tc := newTestController(t, sc)
+	if err := tc.reconcileSC(ctx, "default/prune"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "prune", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret.Data["foreign"] = []byte("kept")
+	if _, err := tc.kclient.CoreV1().Secrets("default").Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "prune", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Spec.TokenFields = []string{"token"}
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/prune"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "prune", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := secret.Data["old"]; ok {
+		t.Errorf("expected key removed from the claim to be pruned, got %v", secret.Data)
+	}
+	for _, k := range []string{"token", "user", "foreign"} {
+		if _, ok := secret.Data[k]; !ok {
+			t.Errorf("expected key %q to be kept, got %v", k, secret.Data)
+		}
+	}
+}
+
+func TestSecretApplyConfiguration(t *testing.T) {
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "templated", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			Immutable: true,
+			SecretTemplate: &v1beta1.SecretTemplate{
+				Type:        corev1.SecretTypeBasicAuth,
+				Labels:      map[string]string{"app": "db"},
+				Annotations: map[string]string{"team": "storage"},
+			},
+		},
+	}
+	got := secretApplyConfiguration(sc, "templated-1234", map[string][]byte{"username": []byte("admin")})
+	want := corev1ac.Secret("templated-1234", "default").
+		WithType(corev1.SecretTypeBasicAuth).
+		WithImmutable(true).
+		WithLabels(map[string]string{"app": "db", v1beta1.LabelClaimUID: "claim-uid"}).
+		WithAnnotations(map[string]string{"team": "storage"}).
+		WithOwnerReferences(metav1ac.OwnerReference().
+			WithAPIVersion("dolansoft.org/v1beta1").
+			WithKind("SecretClaim").
+			WithName("templated").
+			WithUID("claim-uid").
+			WithController(true).
+			WithBlockOwnerDeletion(true)).
+		WithData(map[string][]byte{"username": []byte("admin")})
+	if !equality.Semantic.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("secretApplyConfiguration() = %s, want %s", gotJSON, wantJSON)
+	}
+}
+
+func TestFieldConflict(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "conflict", Namespace: "default", UID: "claim-uid"},
+		Spec:       v1beta1.SecretClaimSpec{FixedFields: map[string]string{"user": "test"}},
+	}
+	tc := newTestController(t, sc)
+	if err := tc.reconcileSC(ctx, "default/conflict"); err != nil {
+		t.Fatal(err)
+	}
+	// Another field manager takes over the field by changing its value
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "conflict", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := secret.DeepCopy()
+	changed.Data["user"] = []byte("admin")
+	updated, err := secretFieldManager(t).Update(secret, changed, "kubectl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err = toSecret(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tc.kclient.Tracker().Update(corev1.SchemeGroupVersion.WithResource("secrets"), changed, "default"); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/conflict"); errorReason(err) != reasonFieldConflict {
+		t.Fatalf("expected field conflict, got %v", err)
+	}
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "conflict", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready := meta.FindStatusCondition(sc.Status.Conditions, v1beta1.ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != reasonFieldConflict {
+		t.Errorf("expected Ready condition to report the conflict, got %v", ready)
+	}
+}
+
+func TestSecretTemplate(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			FixedFields: map[string]string{"username": "test"},
+			TokenFields: []string{"password"},
+			SecretTemplate: &v1beta1.SecretTemplate{
+				Name:        "credentials",
+				Type:        corev1.SecretTypeBasicAuth,
+				Labels:      map[string]string{"app": "test"},
+				Annotations: map[string]string{"old": "annotation"},
+			},
+		},
+	}
+	tc := newTestController(t, sc)
+	if err := tc.reconcileSC(ctx, "default/template"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "credentials", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Type != corev1.SecretTypeBasicAuth || secret.Labels["app"] != "test" || secret.Annotations["old"] != "annotation" {
+		t.Errorf("secret does not match template: %v", secret)
+	}
+	password := string(secret.Data["password"])
+
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "template", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Status.SecretName != "credentials" {
+		t.Errorf("expected status to record secret name, got %q", sc.Status.SecretName)
+	}
+	sc.Spec.SecretTemplate.Type = corev1.SecretTypeOpaque
+	sc.Spec.SecretTemplate.Annotations = map[string]string{"new": "annotation"}
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	recorder := events.NewFakeRecorder(10)
+	tc.recorder = recorder
+	if err := tc.reconcileSC(ctx, "default/template"); err != nil {
+		t.Fatal(err)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Warning SecretRecreating") {
+		t.Errorf("expected warning before the secret is deleted, got %q", event)
+	}
+	tc.recorder = &events.FakeRecorder{}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "credentials", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Type != corev1.SecretTypeOpaque {
+		t.Errorf("expected secret to be recreated with new type, got %v", secret.Type)
+	}
+	if string(secret.Data["password"]) != password {
+		t.Errorf("expected data to be kept when recreating the secret")
+	}
+	if _, ok := secret.Annotations["old"]; ok || secret.Annotations["new"] != "annotation" {
+		t.Errorf("expected annotations to be updated, got %v", secret.Annotations)
+	}
+
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "template", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Spec.SecretTemplate.Name = "renamed"
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/template"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "renamed", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected renamed secret to be created: %v", err)
+	}
+	if string(secret.Data["password"]) != password {
+		t.Errorf("expected data to be carried over to the renamed secret")
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "credentials", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected previous secret to be deleted, got %v", err)
+	}
+}
+
+func TestImmutableSecrets(t *testing.T) {
+	ctx := context.Background()
+	var limit int32 = 1
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "immutable", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields:          []string{"token"},
+			FixedFields:          map[string]string{"version": "0"},
+			Immutable:            true,
+			RevisionHistoryLimit: &limit,
+		},
+	}
+	tc := newTestController(t, sc)
+	var names []string
+	var token string
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			sc, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "immutable", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sc.Spec.FixedFields["version"] = fmt.Sprint(i)
+			if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			tc.syncCaches(t)
+		}
+		if err := tc.reconcileSC(ctx, "default/immutable"); err != nil {
+			t.Fatal(err)
+		}
+		sc, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "immutable", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, sc.Status.SecretName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("current secret %q does not exist: %v", sc.Status.SecretName, err)
+		}
+		if secret.Immutable == nil || !*secret.Immutable {
+			t.Errorf("expected secret %q to be immutable", secret.Name)
+		}
+		if i == 0 {
+			token = string(secret.Data["token"])
+		} else if string(secret.Data["token"]) != token {
+			t.Errorf("expected token to be carried over to new generation")
+		}
+		names = append(names, secret.Name)
+		tc.syncCaches(t)
+	}
+	if names[0] == names[1] || names[1] == names[2] {
+		t.Fatalf("expected a new secret for every generation, got %v", names)
+	}
+	secrets, err := tc.kclient.CoreV1().Secrets("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets.Items) != 2 {
+		t.Errorf("expected current and one previous generation to be kept, got %d secrets", len(secrets.Items))
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected no API calls when everything is up to date, got %d", calls)
+	}
+
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "immutable", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Spec.Immutable = false
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/immutable"); err != nil {
+		t.Fatal(err)
+	}
+	secrets, err = tc.kclient.CoreV1().Secrets("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets.Items) != 1 || secrets.Items[0].Name != "immutable" {
+		t.Errorf("expected all generations to be replaced by a mutable secret, got %v", secrets.Items)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	ctx := context.Background()
+	allowAll := map[string]string{v1beta1.AnnotationAllowReplicationFrom: "*"}
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "replicated", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"password"},
+			Replication: &v1beta1.Replication{
+				Namespaces:        []string{"app"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "db"}},
+			},
+		},
+	}
+	tc := newTestController(t, sc,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Annotations: map[string]string{v1beta1.AnnotationAllowReplicationFrom: "other, default"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: map[string]string{"team": "db"}, Annotations: allowAll}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unwilling", Labels: map[string]string{"team": "db"}}},
+	)
+	reconcile := func() *v1beta1.SecretClaim {
+		t.Helper()
+		tc.syncCaches(t)
+		if err := tc.reconcileSC(ctx, "default/replicated"); err != nil {
+			t.Fatal(err)
+		}
+		sc, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "replicated", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sc
+	}
+	sc = reconcile()
+	if !hasFinalizer(sc, v1beta1.FinalizerReplicas) {
+		t.Errorf("expected replicas finalizer, got %v", sc.Finalizers)
+	}
+	if fmt.Sprint(sc.Status.ReplicatedNamespaces) != "[app db]" {
+		t.Errorf("expected replicas in app and db, got %v", sc.Status.ReplicatedNamespaces)
+	}
+	replicated := meta.FindStatusCondition(sc.Status.Conditions, v1beta1.ConditionReplicated)
+	if replicated == nil || replicated.Reason != reasonReplicationNotAllowed {
+		t.Errorf("expected replication into unwilling namespace to be denied, got %v", replicated)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "replicated", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, err := tc.kclient.CoreV1().Secrets("db").Get(ctx, "replicated", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(replica.Data["password"]) != string(secret.Data["password"]) {
+		t.Errorf("replica does not match secret")
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("unwilling").Get(ctx, "replicated", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no replica in unwilling namespace, got %v", err)
+	}
+
+	sc.Spec.Replication.Namespaces = nil
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sc = reconcile()
+	if _, err := tc.kclient.CoreV1().Secrets("app").Get(ctx, "replicated", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected replica in namespace which is no longer selected to be deleted, got %v", err)
+	}
+
+	now := metav1.Now()
+	sc.DeletionTimestamp = &now
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	sc = reconcile()
+	if _, err := tc.kclient.CoreV1().Secrets("db").Get(ctx, "replicated", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected replicas to be deleted with the claim, got %v", err)
+	}
+	if len(sc.Finalizers) != 0 {
+		t.Errorf("expected finalizers to be removed, got %v", sc.Finalizers)
+	}
+}
+
+func TestValueFromFields(t *testing.T) {
+	ctx := context.Background()
+	optional := true
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "combined", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token"},
+			ValueFromFields: map[string]v1beta1.ValueFromSource{
+				"password": {SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "external"}, Key: "password"}},
+				"host":     {ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}, Key: "host"}},
+				"port": {ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "config"}, Key: "port", Optional: &optional,
+				}},
+			},
+		},
+	}
+	tc := newTestController(t, sc,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default"}, Data: map[string][]byte{"password": []byte("hunter2")}},
+	)
+	if err := tc.reconcileSC(ctx, "default/combined"); errorReason(err) != reasonSourceUnavailable {
+		t.Fatalf("expected missing config map to be reported, got %v", err)
+	}
+	if _, err := tc.kclient.CoreV1().ConfigMaps("default").Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+		Data:       map[string]string{"host": "db.example.com"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if keys := tc.referencingSCs(configMapSourceKey("default", "config")); len(keys) != 1 {
+		t.Errorf("expected claim to be indexed by referenced config map, got %v", keys)
+	}
+	if err := tc.reconcileSC(ctx, "default/combined"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "combined", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["password"]) != "hunter2" || string(secret.Data["host"]) != "db.example.com" || len(secret.Data["token"]) == 0 {
+		t.Errorf("unexpected secret data %v", secret.Data)
+	}
+	if _, ok := secret.Data["port"]; ok {
+		t.Errorf("expected missing optional key to be left out")
+	}
+
+	external, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "external", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	external.Data["password"] = []byte("correcthorse")
+	if _, err := tc.kclient.CoreV1().Secrets("default").Update(ctx, external, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/combined"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "combined", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["password"]) != "correcthorse" {
+		t.Errorf("expected secret to follow referenced secret, got %q", secret.Data["password"])
+	}
+}
+
+func TestUnmanagedSecrets(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "combined", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			ValueFromFields: map[string]v1beta1.ValueFromSource{
+				"password": {SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "external"}, Key: "password"}},
+			},
+		},
+	}
+	tc := newTestController(t, sc,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default"}, Data: map[string][]byte{"password": []byte("hunter2")}},
+	)
+	if err := tc.reconcileSC(ctx, "default/combined"); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if _, err := tc.secretLister.Secrets("default").Get("external"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected referenced secret without label not to be cached in full, got %v", err)
+	}
+	secret, err := tc.secretLister.Secrets("default").Get("combined")
+	if err != nil {
+		t.Fatalf("expected generated secret to be cached: %v", err)
+	}
+	if uid := secret.Labels[v1beta1.LabelClaimUID]; uid != "claim-uid" {
+		t.Errorf("expected generated secret to be labeled with the claim UID, got %q", uid)
+	}
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected unchanged referenced secret to be served from cache, got %d API calls", calls)
+	}
+
+	external, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "external", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	external.Data["password"] = []byte("correcthorse")
+	if _, err := tc.kclient.CoreV1().Secrets("default").Update(ctx, external, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 2 {
+		t.Errorf("expected changed referenced secret to be fetched and applied, got %d API calls", calls)
+	}
+}
+
+func TestUnlabeledSecretOfOlderVersion(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default", UID: "claim-uid"},
+		Spec:       v1beta1.SecretClaimSpec{TokenFields: []string{"token"}},
+	}
+	tc := newTestController(t, sc, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default", OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(sc, v1beta1.SchemeGroupVersion.WithKind(v1beta1.Kind)),
+		}},
+		Data: map[string][]byte{"token": []byte("existing")},
+	})
+	if err := tc.reconcileSC(ctx, "default/old"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "old", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["token"]) != "existing" {
+		t.Errorf("expected token of unlabeled secret to be kept, got %q", secret.Data["token"])
+	}
+	if uid := secret.Labels[v1beta1.LabelClaimUID]; uid != "claim-uid" {
+		t.Errorf("expected secret to be labeled with the claim UID, got %q", uid)
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected no API calls once the secret is labeled, got %d", calls)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+	minBits := int32(128)
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "weak", Namespace: "team-a", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"pin": {Length: 4, Encoding: "characterset", CharacterSet: "0123456789"}},
+		},
+	}
+	policy := &v1beta1.SecretClaimPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strong-tokens"},
+		Spec: v1beta1.SecretClaimPolicySpec{
+			NamespaceSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			MinTokenEntropyBits: &minBits,
+		},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}}
+	tc := newTestController(t, sc, policy, namespace)
+	if err := tc.reconcileSC(ctx, "team-a/weak"); errorReason(err) != reasonPolicyViolation {
+		t.Fatalf("expected policy violation, got %v", err)
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("team-a").Get(ctx, "weak", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no secret to be created, got %v", err)
+	}
+	got, err := tc.dsclient.DolansoftV1beta1().SecretClaims("team-a").Get(ctx, "weak", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, v1beta1.ConditionReady); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != reasonPolicyViolation {
+		t.Errorf("unexpected Ready condition %+v", cond)
+	}
+
+	// The policy no longer applies once the namespace stops matching its selector
+	namespace.Labels = nil
+	if _, err := tc.kclient.CoreV1().Namespaces().Update(ctx, namespace, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "team-a/weak"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("team-a").Get(ctx, "weak", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected secret to be created, got %v", err)
+	}
+}
+
+func TestMinTokenEntropy(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pin", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{
+				"pin":      {Length: 6, Encoding: "characterset", CharacterSet: "0123456789"},
+				"password": {Length: 16, Encoding: "base64"},
+			},
+		},
+	}
+	tc := newTestController(t, sc)
+	tc.minTokenEntropyBits = 64
+	if err := tc.reconcileSC(ctx, "default/pin"); errorReason(err) != reasonInsufficientEntropy {
+		t.Fatalf("expected weak token to be rejected, got %v", err)
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "pin", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no secret to be created, got %v", err)
+	}
+	got, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "pin", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int32{"pin": 19, "password": 128}; !reflect.DeepEqual(got.Status.TokenEntropyBits, want) {
+		t.Errorf("status.tokenEntropyBits = %v, want %v", got.Status.TokenEntropyBits, want)
+	}
+
+	got.Spec.CustomTokenFields["pin"] = v1beta1.CustomTokenSpec{Length: 6, Encoding: "characterset", CharacterSet: "0123456789", AllowLowEntropy: true}
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, got, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/pin"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "pin", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret.Data["pin"]) != 6 {
+		t.Errorf("unexpected pin %q", secret.Data["pin"])
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected no API calls on resync, got %d", calls)
+	}
+}
+
+func TestPassphraseWordListFromConfigMap(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "wifi", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"psk": {Length: 3, Encoding: "passphrase", Passphrase: &v1beta1.PassphraseSpec{
+				WordListRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "words"}, Key: "list"},
+			}}},
+		},
+	}
+	tc := newTestController(t, sc)
+	if err := tc.reconcileSC(ctx, "default/wifi"); errorReason(err) != reasonSourceUnavailable {
+		t.Fatalf("expected missing word list to be reported, got %v", err)
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "words", Namespace: "default"},
+		Data:       map[string]string{"list": "alpha\nbravo\n"},
+	}
+	if _, err := tc.kclient.CoreV1().ConfigMaps("default").Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if keys := tc.referencingSCs(configMapSourceKey("default", "words")); len(keys) != 1 {
+		t.Errorf("expected claim to be indexed by its word list, got %v", keys)
+	}
+	if err := tc.reconcileSC(ctx, "default/wifi"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "wifi", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	psk := string(secret.Data["psk"])
+	for _, word := range strings.Split(psk, "-") {
+		if word != "alpha" && word != "bravo" {
+			t.Fatalf("unexpected passphrase %q", psk)
+		}
+	}
+	tokenEntropyBits := func() map[string]int32 {
+		got, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "wifi", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got.Status.TokenEntropyBits
+	}
+	if got, want := tokenEntropyBits(), map[string]int32{"psk": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("status.tokenEntropyBits = %v, want %v", got, want)
+	}
+
+	// Adding words keeps the passphrase and only updates its entropy, removing the words it consists of replaces it
+	configMap.Data["list"] = "alpha\nbravo\ncharlie\n"
+	if _, err := tc.kclient.CoreV1().ConfigMaps("default").Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/wifi"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tokenEntropyBits(), map[string]int32{"psk": 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("status.tokenEntropyBits = %v, want %v", got, want)
+	}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "wifi", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["psk"]) != psk {
+		t.Errorf("expected passphrase to be kept after adding words, got %q", secret.Data["psk"])
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected no API calls on resync, got %d", calls)
+	}
+	configMap.Data["list"] = "charlie\ndelta\n"
+	if _, err := tc.kclient.CoreV1().ConfigMaps("default").Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/wifi"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "wifi", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["psk"]) == psk || strings.Contains(string(secret.Data["psk"]), "alpha") || strings.Contains(string(secret.Data["psk"]), "bravo") {
+		t.Errorf("expected passphrase to be regenerated from the new word list, got %q", secret.Data["psk"])
+	}
+}
+
+func TestPassphraseWordListMinEntropy(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "wifi", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"psk": {Length: 8, Encoding: "passphrase", Passphrase: &v1beta1.PassphraseSpec{
+				WordListRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "words"}, Key: "list"},
+			}}},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "words", Namespace: "default"},
+		Data:       map[string]string{"list": "alpha\nbravo\ncharlie\ndelta\n"},
+	}
+	tc := newTestController(t, sc, configMap)
+	tc.minTokenEntropyBits = 20
+	if err := tc.reconcileSC(ctx, "default/wifi"); errorReason(err) != reasonInsufficientEntropy {
+		t.Fatalf("expected passphrase from a small word list to be rejected, got %v", err)
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "wifi", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no secret to be created, got %v", err)
+	}
+	got, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "wifi", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int32{"psk": 16}; !reflect.DeepEqual(got.Status.TokenEntropyBits, want) {
+		t.Errorf("status.tokenEntropyBits = %v, want %v", got.Status.TokenEntropyBits, want)
+	}
+
+	configMap.Data["list"] = "alpha\nbravo\ncharlie\ndelta\necho\nfoxtrot\ngolf\nhotel\n"
+	if _, err := tc.kclient.CoreV1().ConfigMaps("default").Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/wifi"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "wifi", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected secret to be created, got %v", err)
+	}
+}
+
+func TestOTPFields(t *testing.T) {
+	ctx := context.Background()
+	sc := &v1beta1.SecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci-bot", Namespace: "default", UID: "claim-uid"},
+		Spec: v1beta1.SecretClaimSpec{
+			OTPFields: map[string]v1beta1.OTPSpec{"totp": {Issuer: "Example Corp", AccountName: "ci-bot@example.com", QRCode: true}},
+		},
+	}
+	tc := newTestController(t, sc)
+	if err := tc.reconcileSC(ctx, "default/ci-bot"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := tc.kclient.CoreV1().Secrets("default").Get(ctx, "ci-bot", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := string(secret.Data["totp"])
+	if len(seed) != 32 {
+		t.Errorf("expected a 160 bit seed, got %q", seed)
+	}
+	wantURI := "otpauth://totp/Example%20Corp:ci-bot%40example.com?secret=" + seed + "&issuer=Example%20Corp&algorithm=SHA1&digits=6&period=30"
+	if uri := string(secret.Data["totp.uri"]); uri != wantURI {
+		t.Errorf("got URI %q, want %q", uri, wantURI)
+	}
+	if png := secret.Data["totp.png"]; !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("expected a PNG QR code, got %d bytes", len(png))
+	}
+	tc.syncCaches(t)
+	if calls := tc.resync(t); calls != 0 {
+		t.Errorf("expected no API calls in steady state, got %d", calls)
+	}
+
+	// Changing the issuer updates the URI and QR code, but keeps the seed
+	sc, err = tc.dsclient.DolansoftV1beta1().SecretClaims("default").Get(ctx, "ci-bot", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.Spec.OTPFields = map[string]v1beta1.OTPSpec{"totp": {Issuer: "Example", AccountName: "ci-bot", Digits: 8}}
+	if _, err := tc.dsclient.DolansoftV1beta1().SecretClaims("default").Update(ctx, sc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tc.syncCaches(t)
+	if err := tc.reconcileSC(ctx, "default/ci-bot"); err != nil {
+		t.Fatal(err)
+	}
+	secret, err = tc.kclient.CoreV1().Secrets("default").Get(ctx, "ci-bot", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secret.Data["totp"]) != seed {
+		t.Errorf("expected seed to be kept, got %q instead of %q", secret.Data["totp"], seed)
+	}
+	wantURI = "otpauth://totp/Example:ci-bot?secret=" + seed + "&issuer=Example&algorithm=SHA1&digits=8&period=30"
+	if uri := string(secret.Data["totp.uri"]); uri != wantURI {
+		t.Errorf("got URI %q, want %q", uri, wantURI)
+	}
+	if _, ok := secret.Data["totp.png"]; ok {
+		t.Errorf("expected QR code to be removed")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"time"
+
+	listers "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/listers/dolansoft.org/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const metricsNamespace = "k8s_generic_secrets"
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_total",
+		Help:      "Number of SecretClaim reconciliations by result",
+	}, []string{"result"})
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of SecretClaim reconciliations by result",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+	secretsCreatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "secrets_created_total",
+		Help:      "Number of secrets created",
+	})
+	secretsPatchedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "secrets_patched_total",
+		Help:      "Number of existing secrets patched",
+	})
+	certificatesIssuedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "certificates_issued_total",
+		Help:      "Number of X.509 certificates issued",
+	})
+	certificateExpiryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "", "certificate_expiry_seconds"),
+		"Seconds until the certificate of a SecretClaim expires",
+		[]string{"namespace", "name"}, nil,
+	)
+)
+
+func observeReconcile(start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileTotal.WithLabelValues(result).Inc()
+	reconcileDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+// certificateExpiryCollector exports the time until expiry of all certificates recorded in SecretClaim statuses. It
+// is evaluated at scrape time so the values are always current.
+type certificateExpiryCollector struct {
+	lister listers.SecretClaimLister
+	// now returns the current time and defaults to time.Now
+	now func() time.Time
+}
+
+func (c certificateExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certificateExpiryDesc
+}
+
+func (c certificateExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	claims, err := c.lister.List(labels.Everything())
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(certificateExpiryDesc, err)
+		return
+	}
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	for _, sc := range claims {
+		if sc.Status.Certificate == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(certificateExpiryDesc, prometheus.GaugeValue,
+			sc.Status.Certificate.NotAfter.Sub(now()).Seconds(), sc.Namespace, sc.Name)
+	}
+}
+
+// Workqueue metrics using the same names as the Kubernetes controllers
+var (
+	workqueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "workqueue",
+		Name:      "depth",
+		Help:      "Current depth of the workqueue",
+	}, []string{"name"})
+	workqueueAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "workqueue",
+		Name:      "adds_total",
+		Help:      "Total number of adds handled by the workqueue",
+	}, []string{"name"})
+	workqueueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "workqueue",
+		Name:      "queue_duration_seconds",
+		Help:      "How long in seconds an item stays in the workqueue before being requested",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	workqueueWorkDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Subsystem: "workqueue",
+		Name:      "work_duration_seconds",
+		Help:      "How long in seconds processing an item from the workqueue takes",
+		Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+	}, []string{"name"})
+	workqueueUnfinishedWork = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "workqueue",
+		Name:      "unfinished_work_seconds",
+		Help:      "How many seconds of work has been done that is in progress and hasn't been observed by work_duration",
+	}, []string{"name"})
+	workqueueLongestRunningProcessor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "workqueue",
+		Name:      "longest_running_processor_seconds",
+		Help:      "How many seconds the longest running processor for the workqueue has been running",
+	}, []string{"name"})
+	workqueueRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "workqueue",
+		Name:      "retries_total",
+		Help:      "Total number of retries handled by the workqueue",
+	}, []string{"name"})
+)
+
+func init() {
+	prometheus.MustRegister(reconcileTotal, reconcileDuration, secretsCreatedTotal, secretsPatchedTotal, certificatesIssuedTotal)
+	prometheus.MustRegister(workqueueDepth, workqueueAdds, workqueueLatency, workqueueWorkDuration,
+		workqueueUnfinishedWork, workqueueLongestRunningProcessor, workqueueRetries)
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// workqueueMetricsProvider implements workqueue.MetricsProvider on top of Prometheus
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	listers "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/listers/dolansoft.org/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestCertificateExpiryCollector(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, sc := range []*v1beta1.SecretClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "valid", Namespace: "default"},
+			Status: v1beta1.SecretClaimStatus{Certificate: &v1beta1.CertificateStatus{
+				NotAfter: metav1.NewTime(now.Add(time.Hour)),
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "expired", Namespace: "other"},
+			Status: v1beta1.SecretClaimStatus{Certificate: &v1beta1.CertificateStatus{
+				NotAfter: metav1.NewTime(now.Add(-time.Minute)),
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"},
+		},
+	} {
+		if err := indexer.Add(sc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	collector := certificateExpiryCollector{
+		lister: listers.NewSecretClaimLister(indexer),
+		now:    func() time.Time { return now },
+	}
+	expected := `
+# HELP k8s_generic_secrets_certificate_expiry_seconds Seconds until the certificate of a SecretClaim expires
+# TYPE k8s_generic_secrets_certificate_expiry_seconds gauge
+k8s_generic_secrets_certificate_expiry_seconds{name="expired",namespace="other"} -60
+k8s_generic_secrets_certificate_expiry_seconds{name="valid",namespace="default"} 3600
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWorkqueueMetrics(t *testing.T) {
+	// The metrics are global, so drop those of earlier runs with -count.
+	for _, vec := range []interface{ DeleteLabelValues(...string) bool }{
+		workqueueDepth, workqueueAdds, workqueueLatency, workqueueWorkDuration,
+		workqueueUnfinishedWork, workqueueLongestRunningProcessor, workqueueRetries,
+	} {
+		vec.DeleteLabelValues("metrics-test")
+	}
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Hour, time.Hour), "metrics-test")
+	defer queue.ShutDown()
+	queue.Add("a")
+	queue.Add("b")
+	queue.Add("a")
+	item, _ := queue.Get()
+	queue.AddRateLimited(item)
+	queue.Done(item)
+
+	for _, tt := range []struct {
+		collector prometheus.Collector
+		expected  string
+	}{
+		{workqueueAdds, `
+# HELP workqueue_adds_total Total number of adds handled by the workqueue
+# TYPE workqueue_adds_total counter
+workqueue_adds_total{name="metrics-test"} 2
+`},
+		{workqueueDepth, `
+# HELP workqueue_depth Current depth of the workqueue
+# TYPE workqueue_depth gauge
+workqueue_depth{name="metrics-test"} 1
+`},
+		{workqueueRetries, `
+# HELP workqueue_retries_total Total number of retries handled by the workqueue
+# TYPE workqueue_retries_total counter
+workqueue_retries_total{name="metrics-test"} 1
+`},
+	} {
+		if err := testutil.CollectAndCompare(tt.collector, strings.NewReader(tt.expected)); err != nil {
+			t.Error(err)
+		}
+	}
+	if n := testutil.CollectAndCount(workqueueLatency, "workqueue_queue_duration_seconds"); n != 1 {
+		t.Errorf("expected queue duration of one queue, got %d", n)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	"github.com/skip2/go-qrcode"
+)
+
+// otpQRCodeSize is the width and height of generated QR codes in pixels.
+const otpQRCodeSize = 256
+
+// otpSeedEncoding is the encoding of OTP seeds expected by authenticator apps.
+var otpSeedEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func makeOTPSeed(spec *v1beta1.OTPSpec) (string, error) {
+	raw := make([]byte, spec.SeedLength)
+	if _, err := io.ReadFull(rand.Reader, raw); err != nil {
+		return "", fmt.Errorf("unable to acquire randomness: %w", err)
+	}
+	return otpSeedEncoding.EncodeToString(raw), nil
+}
+
+func otpSeedValid(spec *v1beta1.OTPSpec, seed string) bool {
+	return decodeCanonical(otpSeedEncoding, seed, int(spec.SeedLength))
+}
+
+// otpEscape escapes s for the label and parameters of otpauth URIs, which use %20 instead of + for spaces.
+func otpEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// otpauthURI returns the provisioning URI for the seed in the key URI format of Google Authenticator, see
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format. The spec needs to have its defaults applied.
+func otpauthURI(spec *v1beta1.OTPSpec, seed string) string {
+	label := otpEscape(spec.AccountName)
+	if spec.Issuer != "" {
+		label = otpEscape(spec.Issuer) + ":" + label
+	}
+	params := []string{"secret=" + seed}
+	if spec.Issuer != "" {
+		params = append(params, "issuer="+otpEscape(spec.Issuer))
+	}
+	params = append(params, fmt.Sprintf("algorithm=%s", spec.Algorithm), fmt.Sprintf("digits=%d", spec.Digits))
+	if spec.Type == v1beta1.OTPTypeHOTP {
+		params = append(params, fmt.Sprintf("counter=%d", spec.Counter))
+	} else {
+		params = append(params, fmt.Sprintf("period=%d", spec.Period))
+	}
+	return fmt.Sprintf("otpauth://%s/%s?%s", spec.Type, label, strings.Join(params, "&"))
+}
+
+// otpQRCode renders the provisioning URI as a PNG image. The output only depends on the URI, so it stays the same
+// across reconciles.
+func otpQRCode(uri string) ([]byte, error) {
+	return qrcode.Encode(uri, qrcode.Medium, otpQRCodeSize)
+}
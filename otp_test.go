@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+)
+
+func TestOTPAuthURI(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1beta1.OTPSpec
+		want string
+	}{
+		{"Defaults", v1beta1.OTPSpec{AccountName: "bot"},
+			"otpauth://totp/bot?secret=JBSWY3DPEHPK3PXP&algorithm=SHA1&digits=6&period=30"},
+		{"Escaping", v1beta1.OTPSpec{Issuer: "ACME Co", AccountName: "a+b@example.com/x"},
+			"otpauth://totp/ACME%20Co:a%2Bb%40example.com%2Fx?secret=JBSWY3DPEHPK3PXP&issuer=ACME%20Co&algorithm=SHA1&digits=6&period=30"},
+		{"hotp", v1beta1.OTPSpec{Type: v1beta1.OTPTypeHOTP, Issuer: "Example", AccountName: "bot", Algorithm: v1beta1.OTPAlgorithmSHA512, Digits: 8, Counter: 7},
+			"otpauth://hotp/Example:bot?secret=JBSWY3DPEHPK3PXP&issuer=Example&algorithm=SHA512&digits=8&counter=7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.spec.Default()
+			if got := otpauthURI(&tt.spec, "JBSWY3DPEHPK3PXP"); got != tt.want {
+				t.Errorf("otpauthURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOTPSeed(t *testing.T) {
+	spec := v1beta1.OTPSpec{AccountName: "bot", SeedLength: 32}
+	spec.Default()
+	seed, err := makeOTPSeed(&spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !otpSeedValid(&spec, seed) {
+		t.Errorf("generated seed %q is not valid", seed)
+	}
+	for _, invalid := range []string{"", seed[:len(seed)-1], seed + "====", "jbswy3dpehpk3pxp"} {
+		if otpSeedValid(&spec, invalid) {
+			t.Errorf("expected seed %q to be invalid", invalid)
+		}
+	}
+	first, err := otpQRCode(otpauthURI(&spec, seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := otpQRCode(otpauthURI(&spec, seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("expected QR codes of the same URI to be identical")
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+)
+
+// effLargeWordListText is the EFF large word list for passphrases, see
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases. It is licensed under CC BY 3.0 US.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordListText string
+
+var effLargeWordList = parseWordList(effLargeWordListText)
+
+// parseWordList parses a word list with one word per line. Diceware lists with the dice rolls before the word are
+// supported as well. Empty lines and lines starting with # are ignored, duplicate words are removed and all words are
+// lower-cased.
+func parseWordList(text string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := strings.ToLower(fields[len(fields)-1])
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// wordListSize returns the number of words in a list and how many of them change in title case.
+func wordListSize(words []string) v1beta1.WordListSize {
+	size := v1beta1.WordListSize{Words: len(words)}
+	for _, word := range words {
+		if titleCase(word) != word {
+			size.Capitalizable++
+		}
+	}
+	return size
+}
+
+// passphraseOptions returns the separator and capitalization of passphrases, applying the defaults.
+func passphraseOptions(spec *v1beta1.CustomTokenSpec) (string, v1beta1.Capitalization) {
+	separator, capitalization := v1beta1.DefaultPassphraseSeparator, v1beta1.CapitalizationLower
+	if p := spec.Passphrase; p != nil {
+		if p.Separator != nil {
+			separator = *p.Separator
+		}
+		if p.Capitalization != "" {
+			capitalization = p.Capitalization
+		}
+	}
+	return separator, capitalization
+}
+
+func titleCase(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// makePassphrase joins Length words drawn uniformly from the word list.
+func makePassphrase(spec *v1beta1.CustomTokenSpec, words []string) (string, error) {
+	if len(words) < 2 {
+		return "", fmt.Errorf("word list needs to contain at least 2 words, got %d", len(words))
+	}
+	separator, capitalization := passphraseOptions(spec)
+	chosen := make([]string, spec.Length)
+	for i := range chosen {
+		n, err := randomIndex(len(words))
+		if err != nil {
+			return "", err
+		}
+		word := words[n]
+		switch capitalization {
+		case v1beta1.CapitalizationTitle:
+			word = titleCase(word)
+		case v1beta1.CapitalizationUpper:
+			word = strings.ToUpper(word)
+		case v1beta1.CapitalizationRandom:
+			coin, err := randomIndex(2)
+			if err != nil {
+				return "", err
+			}
+			if coin == 1 {
+				word = titleCase(word)
+			}
+		}
+		chosen[i] = word
+	}
+	return strings.Join(chosen, separator), nil
+}
+
+// passphraseValid checks if a token consists of Length words of the word list joined by the separator. As words may
+// contain the separator (like t-shirt in the EFF list), it checks all ways of splitting the token into words.
+func passphraseValid(spec *v1beta1.CustomTokenSpec, words []string, token string) bool {
+	separator, capitalization := passphraseOptions(spec)
+	dict := make(map[string]bool, len(words))
+	maxWordLength := 0
+	for _, word := range words {
+		dict[word] = true
+		if len(word) > maxWordLength {
+			maxWordLength = len(word)
+		}
+	}
+	isWord := func(s string) bool {
+		lower := strings.ToLower(s)
+		if !dict[lower] {
+			return false
+		}
+		switch capitalization {
+		case v1beta1.CapitalizationTitle:
+			return s == titleCase(lower)
+		case v1beta1.CapitalizationUpper:
+			return s == strings.ToUpper(lower)
+		case v1beta1.CapitalizationRandom:
+			return s == lower || s == titleCase(lower)
+		default:
+			return s == lower
+		}
+	}
+	n := int(spec.Length)
+	// wordCounts[i] holds the numbers of words which token[:i] can be split into, followed by a separator
+	wordCounts := make([]map[int]bool, len(token)+1)
+	wordCounts[0] = map[int]bool{0: true}
+	for start := range wordCounts {
+		for count := range wordCounts[start] {
+			for end := start + 1; end <= len(token) && end-start <= maxWordLength; end++ {
+				if !isWord(token[start:end]) {
+					continue
+				}
+				if end == len(token) && count+1 == n {
+					return true
+				}
+				if next := end + len(separator); count+1 < n && strings.HasPrefix(token[end:], separator) && next <= len(token) {
+					if wordCounts[next] == nil {
+						wordCounts[next] = make(map[int]bool)
+					}
+					wordCounts[next][count+1] = true
+				}
+			}
+		}
+	}
+	return false
+}
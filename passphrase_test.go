@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+)
+
+func TestEFFLargeWordList(t *testing.T) {
+	if len(effLargeWordList) != v1beta1.EFFLargeWordListSize {
+		t.Errorf("embedded word list has %d words, want %d", len(effLargeWordList), v1beta1.EFFLargeWordListSize)
+	}
+	if effLargeWordList[0] != "abacus" || effLargeWordList[len(effLargeWordList)-1] != "zoom" {
+		t.Errorf("unexpected words %q ... %q", effLargeWordList[0], effLargeWordList[len(effLargeWordList)-1])
+	}
+	if got := wordListSize(effLargeWordList); got.Capitalizable != v1beta1.EFFLargeWordListSize {
+		t.Errorf("expected all words of the embedded word list to change in title case, got %d", got.Capitalizable)
+	}
+}
+
+func TestWordListSize(t *testing.T) {
+	if got, want := wordListSize([]string{"apple", "42", "über", "-"}), (v1beta1.WordListSize{Words: 4, Capitalizable: 2}); got != want {
+		t.Errorf("wordListSize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	text := "# Diceware list\n11111\tapple\n\n11112 Banana\r\ncherry\napple\n"
+	if got, want := parseWordList(text), []string{"apple", "banana", "cherry"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWordList() = %v, want %v", got, want)
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	separator := " "
+	tests := []struct {
+		name       string
+		passphrase *v1beta1.PassphraseSpec
+		valid      []string
+		invalid    []string
+	}{
+		{"Defaults", nil,
+			[]string{"t-shirt-yo-yo-abacus", "abacus-zoom-zoom"},
+			[]string{"abacus-zoom", "abacus-zoom-zoom-zoom", "Abacus-zoom-zoom", "abacus zoom zoom", "abacus-zoom-notaword", "abacus--zoom-zoom"}},
+		{"Title case with space", &v1beta1.PassphraseSpec{Separator: &separator, Capitalization: v1beta1.CapitalizationTitle},
+			[]string{"Abacus Zoom T-shirt"},
+			[]string{"abacus Zoom T-shirt", "Abacus-Zoom-T-shirt"}},
+		{"Upper case", &v1beta1.PassphraseSpec{Capitalization: v1beta1.CapitalizationUpper},
+			[]string{"ABACUS-ZOOM-T-SHIRT"},
+			[]string{"ABACUS-ZOOM-T-shirt"}},
+		{"Random capitalization", &v1beta1.PassphraseSpec{Capitalization: v1beta1.CapitalizationRandom},
+			[]string{"Abacus-zoom-T-shirt", "abacus-zoom-t-shirt"},
+			[]string{"ABACUS-zoom-t-shirt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1beta1.CustomTokenSpec{Length: 3, Encoding: "passphrase", Passphrase: tt.passphrase}
+			for i := 0; i < 20; i++ {
+				token, err := makeCustomToken(spec, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !customTokenValid(spec, nil, token) {
+					t.Fatalf("generated passphrase %q is not valid", token)
+				}
+			}
+			for _, token := range tt.valid {
+				if !customTokenValid(spec, nil, token) {
+					t.Errorf("expected %q to be valid", token)
+				}
+			}
+			for _, token := range tt.invalid {
+				if customTokenValid(spec, nil, token) {
+					t.Errorf("expected %q to be invalid", token)
+				}
+			}
+		})
+	}
+}
+
+func TestPassphraseCustomWordList(t *testing.T) {
+	spec := &v1beta1.CustomTokenSpec{Length: 4, Encoding: "passphrase", Prefix: "wifi:"}
+	words := []string{"red", "green", "blue"}
+	token, err := makeCustomToken(spec, words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range strings.Split(strings.TrimPrefix(token, "wifi:"), "-") {
+		if word != "red" && word != "green" && word != "blue" {
+			t.Errorf("passphrase %q contains a word not in the list", token)
+		}
+	}
+	if !customTokenValid(spec, words, token) {
+		t.Errorf("generated passphrase %q is not valid", token)
+	}
+	if customTokenValid(spec, []string{"yellow", "purple"}, token) {
+		t.Errorf("expected %q to be invalid with another word list", token)
+	}
+	if _, err := makeCustomToken(spec, []string{"red"}); err == nil {
+		t.Errorf("expected word list with a single word to be rejected")
+	}
+}
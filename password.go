@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+)
+
+// passwordAlphabet holds the characters of a password policy by class.
+type passwordAlphabet struct {
+	policy  *v1beta1.PasswordPolicy
+	classes [][]rune
+	classOf map[rune]int
+	// Classes allowed for the first and the last position
+	firstMask, lastMask uint32
+}
+
+func newPasswordAlphabet(policy *v1beta1.PasswordPolicy, length int) *passwordAlphabet {
+	a := &passwordAlphabet{policy: policy, classes: policy.ClassRunes(), classOf: make(map[rune]int)}
+	a.firstMask, a.lastMask = policy.PositionClasses()
+	if length == 1 {
+		a.firstMask &= a.lastMask
+		a.lastMask = a.firstMask
+	}
+	for i, class := range a.classes {
+		for _, r := range class {
+			a.classOf[r] = i
+		}
+	}
+	return a
+}
+
+// valid checks if a token only consists of characters allowed at their position and meets the minimum counts.
+func (a *passwordAlphabet) valid(token []rune) bool {
+	counts := make([]int32, len(a.policy.Classes))
+	for i, r := range token {
+		class, ok := a.classOf[r]
+		if !ok {
+			return false
+		}
+		if i == 0 && a.firstMask&(1<<class) == 0 || i == len(token)-1 && a.lastMask&(1<<class) == 0 {
+			return false
+		}
+		counts[class]++
+	}
+	for i, class := range a.policy.Classes {
+		if counts[i] < class.MinCount {
+			return false
+		}
+	}
+	return true
+}
+
+// makePassword generates a token of the given length satisfying the policy. The classes of the first and last
+// character and the number of characters of every class in between are drawn weighted by the number of tokens they
+// allow. Characters are then drawn uniformly from their class and the positions in between are shuffled, so the result
+// is uniformly distributed over all tokens satisfying the policy.
+func makePassword(policy *v1beta1.PasswordPolicy, length int) (string, error) {
+	a := newPasswordAlphabet(policy, length)
+	p := v1beta1.NewPasswordCounts(policy, length)
+	state, middle := p.Initial()
+	if p.Total().Sign() == 0 {
+		return "", fmt.Errorf("no token satisfies the password policy")
+	}
+	token := make([]rune, length)
+	drawn := make([]rune, 0, middle)
+	draw := func(class []rune) (rune, error) {
+		n, err := randomIndex(len(class))
+		if err != nil {
+			return 0, err
+		}
+		return class[n], nil
+	}
+	m := middle
+	for j := range p.Constrained {
+		r, err := rand.Int(rand.Reader, p.Count(j, state, m))
+		if err != nil {
+			return "", fmt.Errorf("unable to acquire randomness: %w", err)
+		}
+		newState, n := state, 0
+		p.Choices(j, state, m, func(weight *big.Int, s, k int) bool {
+			if r.Cmp(weight) < 0 {
+				newState, n = s, k
+				return true
+			}
+			r.Sub(r, weight)
+			return false
+		})
+		class := a.classes[p.Constrained[j]]
+		taken := state &^ newState
+		if taken&v1beta1.FirstOpen != 0 {
+			if token[0], err = draw(class); err != nil {
+				return "", err
+			}
+		}
+		if taken&v1beta1.LastOpen != 0 {
+			if token[length-1], err = draw(class); err != nil {
+				return "", err
+			}
+		}
+		for ; n > 0; n-- {
+			c, err := draw(class)
+			if err != nil {
+				return "", err
+			}
+			drawn = append(drawn, c)
+		}
+		state, m = newState, middle-len(drawn)
+	}
+	var tail, tailFirst, tailLast []rune
+	for i, class := range a.classes {
+		if policy.Classes[i].MinCount > 0 {
+			continue
+		}
+		tail = append(tail, class...)
+		if a.firstMask&(1<<i) != 0 {
+			tailFirst = append(tailFirst, class...)
+		}
+		if a.lastMask&(1<<i) != 0 {
+			tailLast = append(tailLast, class...)
+		}
+	}
+	var err error
+	if state&v1beta1.FirstOpen != 0 {
+		if token[0], err = draw(tailFirst); err != nil {
+			return "", err
+		}
+	}
+	if state&v1beta1.LastOpen != 0 {
+		if token[length-1], err = draw(tailLast); err != nil {
+			return "", err
+		}
+	}
+	for len(drawn) < middle {
+		c, err := draw(tail)
+		if err != nil {
+			return "", err
+		}
+		drawn = append(drawn, c)
+	}
+	for i := len(drawn) - 1; i > 0; i-- {
+		k, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		drawn[i], drawn[k] = drawn[k], drawn[i]
+	}
+	copy(token[1:], drawn)
+	return string(token), nil
+}
+
+// passwordValid checks if an existing token still satisfies the policy.
+func passwordValid(policy *v1beta1.PasswordPolicy, length int, token string) bool {
+	runes := []rune(token)
+	return len(runes) == length && newPasswordAlphabet(policy, length).valid(runes)
+}
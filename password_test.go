@@ -0,0 +1,162 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+)
+
+func TestPasswordPolicyToken(t *testing.T) {
+	spec := &v1beta1.CustomTokenSpec{Length: 8, Encoding: "passwordpolicy", PasswordPolicy: &v1beta1.PasswordPolicy{
+		Classes: []v1beta1.CharacterClass{
+			{Name: "lowercase", MinCount: 1},
+			{Name: "uppercase", MinCount: 1},
+			{Name: "digits", MinCount: 1},
+			{Name: "symbols", MinCount: 1},
+		},
+		ExcludedCharacters:    "0O1lI",
+		FirstCharacterClasses: []string{"lowercase", "uppercase"},
+	}}
+	for i := 0; i < 100; i++ {
+		token, err := makeCustomToken(spec, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !customTokenValid(spec, nil, token) {
+			t.Fatalf("generated token %q is not valid", token)
+		}
+		if len(token) != 8 || unicode.IsDigit(rune(token[0])) || strings.ContainsAny(token, "0O1lI") {
+			t.Fatalf("generated token %q violates the policy", token)
+		}
+	}
+
+	tests := []struct {
+		token string
+		want  bool
+	}{
+		{"aB3$efgh", true},
+		{"3aB$efgh", false}, // Starts with a digit
+		{"aB3$efgI", false}, // Excluded character
+		{"aBc$efgh", false}, // No digit
+		{"aB3$efg", false},  // Too short
+		{"aB3$efghi", false},
+		{"aB3$efgä", false}, // Not in any class
+	}
+	for _, tt := range tests {
+		if got := customTokenValid(spec, nil, tt.token); got != tt.want {
+			t.Errorf("customTokenValid(%q) = %v, want %v", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordPolicyUniform(t *testing.T) {
+	// With a digit required in a two character token from {a, b, 1}, the five valid tokens a1, b1, 1a, 1b and 11 need
+	// to be generated equally often.
+	policy := &v1beta1.PasswordPolicy{Classes: []v1beta1.CharacterClass{
+		{Name: "letters", Characters: "ab"},
+		{Name: "digits", Characters: "1", MinCount: 1},
+	}}
+	counts := make(map[string]int)
+	const n = 5000
+	for i := 0; i < n; i++ {
+		token, err := makePassword(policy, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[token]++
+	}
+	if len(counts) != 5 {
+		t.Fatalf("expected 5 distinct tokens, got %v", counts)
+	}
+	for token, count := range counts {
+		if count < n/5*8/10 || count > n/5*12/10 {
+			t.Errorf("token %q generated %d times out of %d", token, count, n)
+		}
+	}
+}
+
+func TestPasswordPolicyTight(t *testing.T) {
+	// Only about one in 10^26 tokens drawn character by character has 30 digits
+	policy := &v1beta1.PasswordPolicy{Classes: []v1beta1.CharacterClass{
+		{Name: "lowercase"},
+		{Name: "uppercase"},
+		{Name: "digits", MinCount: 30},
+		{Name: "symbols"},
+	}}
+	for i := 0; i < 20; i++ {
+		token, err := makePassword(policy, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !passwordValid(policy, 32, token) {
+			t.Fatalf("generated token %q is not valid", token)
+		}
+	}
+	exact := &v1beta1.PasswordPolicy{
+		Classes: []v1beta1.CharacterClass{
+			{Name: "letters", Characters: "ab", MinCount: 2},
+			{Name: "digits", Characters: "12", MinCount: 2},
+		},
+		FirstCharacterClasses: []string{"digits"},
+		LastCharacterClasses:  []string{"letters"},
+	}
+	for i := 0; i < 20; i++ {
+		token, err := makePassword(exact, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !passwordValid(exact, 4, token) {
+			t.Fatalf("generated token %q is not valid", token)
+		}
+	}
+}
+
+func TestPasswordPolicyUniformPositions(t *testing.T) {
+	// Tokens of length 3 from {a, b, 1} starting with a letter and containing a digit: a1a, a1b, b1a, b1b, a11, b11,
+	// aa1, ab1, ba1 and bb1
+	policy := &v1beta1.PasswordPolicy{
+		Classes: []v1beta1.CharacterClass{
+			{Name: "letters", Characters: "ab"},
+			{Name: "digits", Characters: "1", MinCount: 1},
+		},
+		FirstCharacterClasses: []string{"letters"},
+	}
+	counts := make(map[string]int)
+	const n = 10000
+	for i := 0; i < n; i++ {
+		token, err := makePassword(policy, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[token]++
+	}
+	if len(counts) != 10 {
+		t.Fatalf("expected 10 distinct tokens, got %v", counts)
+	}
+	for token, count := range counts {
+		if count < n/10*8/10 || count > n/10*12/10 {
+			t.Errorf("token %q generated %d times out of %d", token, count, n)
+		}
+	}
+}
+
+func TestPasswordPolicySingleCharacter(t *testing.T) {
+	policy := &v1beta1.PasswordPolicy{
+		Classes: []v1beta1.CharacterClass{
+			{Name: "letters", Characters: "ab"},
+			{Name: "digits", Characters: "12"},
+		},
+		FirstCharacterClasses: []string{"letters"},
+	}
+	for i := 0; i < 20; i++ {
+		token, err := makePassword(policy, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "a" && token != "b" {
+			t.Fatalf("expected a single letter, got %q", token)
+		}
+	}
+}
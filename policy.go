@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// enqueueAllSCs enqueues all claims, for example when a policy changes.
+func (c *controller) enqueueAllSCs() {
+	claims, err := c.scLister.List(labels.Everything())
+	if err != nil {
+		panic(err)
+	}
+	for _, sc := range claims {
+		c.enqueueSC(sc)
+	}
+}
+
+// enqueueNamespaceSCs enqueues all claims in a namespace as the policies applying to them might have changed.
+func (c *controller) enqueueNamespaceSCs(namespace *corev1.Namespace) {
+	claims, err := c.scLister.SecretClaims(namespace.Name).List(labels.Everything())
+	if err != nil {
+		panic(err)
+	}
+	for _, sc := range claims {
+		c.enqueueSC(sc)
+	}
+}
+
+// policyViolations evaluates all policies selecting the namespace against a claim. A nil namespace has no labels.
+func policyViolations(sc *v1beta1.SecretClaim, policies []*v1beta1.SecretClaimPolicy, namespace *corev1.Namespace, wordListSizes v1beta1.WordListSizes) (field.ErrorList, error) {
+	var namespaceLabels labels.Set
+	if namespace != nil {
+		namespaceLabels = namespace.Labels
+	}
+	var errs field.ErrorList
+	for _, policy := range policies {
+		if policy.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespaceSelector of SecretClaimPolicy %s: %w", policy.Name, err)
+			}
+			if !selector.Matches(namespaceLabels) {
+				continue
+			}
+		}
+		errs = append(errs, policy.Evaluate(sc, wordListSizes)...)
+	}
+	return errs, nil
+}
+
+// checkPolicies rejects claims violating a policy which applies to them.
+func (c *controller) checkPolicies(sc *v1beta1.SecretClaim, wordListSizes v1beta1.WordListSizes) error {
+	policies, err := c.policyLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	namespace, err := c.namespaceLister.Get(sc.Namespace)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	errs, err := policyViolations(sc, policies, namespace, wordListSizes)
+	if err != nil {
+		return withReason(reasonPolicyViolation, err)
+	}
+	if len(errs) > 0 {
+		return withReason(reasonPolicyViolation, errs.ToAggregate())
+	}
+	return nil
+}
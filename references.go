@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+)
+
+// referencedSourcesIndex indexes claims by the Secrets and ConfigMaps they reference in valueFromFields, as word lists
+// of passphrases and as CA so that they can be reconciled when one of them changes.
+const referencedSourcesIndex = "referencedSources"
+
+func secretSourceKey(namespace, name string) string {
+	return "secret/" + namespace + "/" + name
+}
+
+func configMapSourceKey(namespace, name string) string {
+	return "configmap/" + namespace + "/" + name
+}
+
+func indexReferencedSources(obj interface{}) ([]string, error) {
+	sc, ok := obj.(*v1beta1.SecretClaim)
+	if !ok {
+		return nil, nil
+	}
+	var keys []string
+	for _, source := range sc.Spec.ValueFromFields {
+		if source.SecretKeyRef != nil {
+			keys = append(keys, secretSourceKey(sc.Namespace, source.SecretKeyRef.Name))
+		}
+		if source.ConfigMapKeyRef != nil {
+			keys = append(keys, configMapSourceKey(sc.Namespace, source.ConfigMapKeyRef.Name))
+		}
+	}
+	if x := sc.Spec.X509Claim; x != nil && x.CASecretName != "" {
+		// Certificates are reissued when their CA is
+		keys = append(keys, secretSourceKey(sc.Namespace, x.CASecretName))
+	}
+	for _, tokenSpec := range sc.Spec.CustomTokenFields {
+		if tokenSpec.Passphrase != nil && tokenSpec.Passphrase.WordListRef != nil {
+			keys = append(keys, configMapSourceKey(sc.Namespace, tokenSpec.Passphrase.WordListRef.Name))
+		}
+	}
+	return keys, nil
+}
+
+// referencingSCs returns the claims referencing the source with the given index key.
+func (c *controller) referencingSCs(key string) []interface{} {
+	claims, err := c.scIndexer.ByIndex(referencedSourcesIndex, key)
+	if err != nil {
+		panic(err)
+	}
+	return claims
+}
+
+// enqueueReferencingSCs enqueues all claims referencing a Secret or ConfigMap.
+func (c *controller) enqueueReferencingSCs(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	var key string
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		key = secretSourceKey(o.Namespace, o.Name)
+	case *corev1.ConfigMap:
+		key = configMapSourceKey(o.Namespace, o.Name)
+	default:
+		return
+	}
+	for _, sc := range c.referencingSCs(key) {
+		c.enqueueSC(sc)
+	}
+}
+
+// referencedValues resolves the valueFromFields of a claim from the cache. Keys of optional references which cannot
+// be resolved are left out.
+func (c *controller) referencedValues(ctx context.Context, sc *v1beta1.SecretClaim) (map[string][]byte, error) {
+	values := make(map[string][]byte)
+	for field, source := range sc.Spec.ValueFromFields {
+		var value []byte
+		var found bool
+		var optional *bool
+		switch {
+		case source.SecretKeyRef != nil && source.ConfigMapKeyRef == nil:
+			ref := source.SecretKeyRef
+			optional = ref.Optional
+			secret, err := c.getSecret(ctx, sc.Namespace, ref.Name)
+			if err != nil {
+				return nil, err
+			}
+			if secret != nil {
+				value, found = secret.Data[ref.Key]
+			}
+			if !found && (optional == nil || !*optional) {
+				return nil, withReason(reasonSourceUnavailable, fmt.Errorf("key \"%s\" of secret \"%s\" referenced by field \"%s\" does not exist", ref.Key, ref.Name, field))
+			}
+		case source.ConfigMapKeyRef != nil && source.SecretKeyRef == nil:
+			ref := source.ConfigMapKeyRef
+			optional = ref.Optional
+			configMap, err := c.configMapLister.ConfigMaps(sc.Namespace).Get(ref.Name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			if err == nil {
+				if v, ok := configMap.Data[ref.Key]; ok {
+					value, found = []byte(v), true
+				} else {
+					value, found = configMap.BinaryData[ref.Key]
+				}
+			}
+			if !found && (optional == nil || !*optional) {
+				return nil, withReason(reasonSourceUnavailable, fmt.Errorf("key \"%s\" of config map \"%s\" referenced by field \"%s\" does not exist", ref.Key, ref.Name, field))
+			}
+		default:
+			return nil, withReason(reasonInvalidSpec, fmt.Errorf("field \"%s\" must reference exactly one of a secret or a config map", field))
+		}
+		if found {
+			values[field] = value
+		}
+	}
+	return values, nil
+}
+
+// referencedWordLists resolves the word lists of passphrases referenced from ConfigMaps by field.
+func (c *controller) referencedWordLists(sc *v1beta1.SecretClaim) (map[string][]string, error) {
+	wordLists := make(map[string][]string)
+	for field, tokenSpec := range sc.Spec.CustomTokenFields {
+		if tokenSpec.Encoding != "passphrase" || tokenSpec.Passphrase == nil || tokenSpec.Passphrase.WordListRef == nil {
+			continue
+		}
+		ref := tokenSpec.Passphrase.WordListRef
+		configMap, err := c.configMapLister.ConfigMaps(sc.Namespace).Get(ref.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		text, found := "", false
+		if err == nil {
+			text, found = configMap.Data[ref.Key]
+		}
+		if !found {
+			return nil, withReason(reasonSourceUnavailable, fmt.Errorf("key \"%s\" of config map \"%s\" referenced as word list by field \"%s\" does not exist", ref.Key, ref.Name, field))
+		}
+		words := parseWordList(text)
+		if len(words) < 2 {
+			return nil, withReason(reasonSourceUnavailable, fmt.Errorf("word list \"%s\" of config map \"%s\" referenced by field \"%s\" needs to contain at least 2 words", ref.Key, ref.Name, field))
+		}
+		wordLists[field] = words
+	}
+	return wordLists, nil
+}
+
+// wordListSizes returns the size of each resolved word list.
+func wordListSizes(wordLists map[string][]string) v1beta1.WordListSizes {
+	sizes := make(v1beta1.WordListSizes, len(wordLists))
+	for field, words := range wordLists {
+		sizes[field] = wordListSize(words)
+	}
+	return sizes
+}
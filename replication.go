@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// enqueueReplicatingSCs enqueues all claims which replicate their secret when a namespace changes, as it might have
+// become (or stopped being) a replication target.
+func (c *controller) enqueueReplicatingSCs(obj interface{}) {
+	claims, err := c.scLister.List(labels.Everything())
+	if err != nil {
+		panic(err)
+	}
+	for _, sc := range claims {
+		if sc.Spec.Replication != nil || len(sc.Status.ReplicatedNamespaces) > 0 {
+			c.enqueueSC(sc)
+		}
+	}
+}
+
+// replicaSource returns the key of the claim a replicated secret belongs to, if any.
+func replicaSource(secret *corev1.Secret) (string, bool) {
+	if _, ok := secret.Labels[v1beta1.LabelReplicaOf]; !ok {
+		return "", false
+	}
+	key, ok := secret.Annotations[v1beta1.AnnotationReplicaOf]
+	return key, ok
+}
+
+// replicationAllowed checks if a namespace has consented to claims in the source namespace replicating into it.
+func replicationAllowed(namespace *corev1.Namespace, source string) bool {
+	allowed, ok := namespace.Annotations[v1beta1.AnnotationAllowReplicationFrom]
+	if !ok {
+		return false
+	}
+	for _, ns := range strings.Split(allowed, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "*" || ns == source {
+			return true
+		}
+	}
+	return false
+}
+
+// replicationTargets returns the sorted namespaces into which the secret of a claim is replicated as well as the ones
+// which are selected by the claim, but have not consented to replication.
+func (c *controller) replicationTargets(sc *v1beta1.SecretClaim) (targets, denied []string, err error) {
+	replication := sc.Spec.Replication
+	if replication == nil {
+		return nil, nil, nil
+	}
+	selected := make(map[string]*corev1.Namespace)
+	for _, name := range replication.Namespaces {
+		ns, err := c.namespaceLister.Get(name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		selected[name] = ns
+	}
+	if replication.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(replication.NamespaceSelector)
+		if err != nil {
+			return nil, nil, withReason(reasonInvalidSpec, fmt.Errorf("invalid namespaceSelector: %w", err))
+		}
+		namespaces, err := c.namespaceLister.List(selector)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, ns := range namespaces {
+			selected[ns.Name] = ns
+		}
+	}
+	for name, ns := range selected {
+		if name == sc.Namespace {
+			continue
+		}
+		if replicationAllowed(ns, sc.Namespace) {
+			targets = append(targets, name)
+		} else {
+			denied = append(denied, name)
+		}
+	}
+	sort.Strings(targets)
+	sort.Strings(denied)
+	return targets, denied, nil
+}
+
+// reconcileReplicas copies the secret of a claim into all namespaces selected for replication and removes replicas
+// from namespaces which are no longer selected or have revoked their consent.
+func (c *controller) reconcileReplicas(ctx context.Context, sc *v1beta1.SecretClaim, status *v1beta1.SecretClaimStatus, name string, data map[string][]byte) error {
+	targets, denied, err := c.replicationTargets(sc)
+	if err != nil {
+		return err
+	}
+	var replicated, conflicts []string
+	for _, namespace := range targets {
+		existing, err := c.getSecret(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.Labels[v1beta1.LabelReplicaOf] != string(sc.UID) {
+			conflicts = append(conflicts, namespace)
+			continue
+		}
+		if err := c.applyReplica(ctx, sc, namespace, name, existing, data); err != nil {
+			return err
+		}
+		replicated = append(replicated, namespace)
+	}
+
+	current := make(map[string]bool)
+	for _, namespace := range replicated {
+		current[namespace] = true
+	}
+	replicas, err := c.listSecretMetadata(labels.SelectorFromSet(labels.Set{v1beta1.LabelReplicaOf: string(sc.UID)}))
+	if err != nil {
+		return err
+	}
+	for _, replica := range replicas {
+		if current[replica.Namespace] && replica.Name == name {
+			continue
+		}
+		if err := c.deleteSecret(ctx, sc, replica, "it is no longer replicated"); err != nil {
+			return err
+		}
+	}
+	status.ReplicatedNamespaces = replicated
+
+	switch {
+	case sc.Spec.Replication == nil:
+		meta.RemoveStatusCondition(&status.Conditions, v1beta1.ConditionReplicated)
+	case len(conflicts) > 0:
+		setCondition(sc, status, v1beta1.ConditionReplicated, metav1.ConditionFalse, reasonReplicaConflict,
+			fmt.Sprintf("Secrets not created by this claim already exist in namespaces %s", strings.Join(conflicts, ", ")))
+	case len(denied) > 0:
+		setCondition(sc, status, v1beta1.ConditionReplicated, metav1.ConditionFalse, reasonReplicationNotAllowed,
+			fmt.Sprintf("Namespaces %s do not allow replication from %s, annotate them with %s", strings.Join(denied, ", "), sc.Namespace, v1beta1.AnnotationAllowReplicationFrom))
+	default:
+		setCondition(sc, status, v1beta1.ConditionReplicated, metav1.ConditionTrue, reasonReplicated,
+			fmt.Sprintf("Secret is replicated to %d namespaces", len(replicated)))
+	}
+	return nil
+}
+
+// applyReplica writes a copy of the secret of a claim into another namespace using server-side apply. As owner
+// references cannot point to other namespaces, replicas are tracked by their labels instead.
+func (c *controller) applyReplica(ctx context.Context, sc *v1beta1.SecretClaim, namespace, name string, existing *corev1.Secret, data map[string][]byte) error {
+	key, err := cache.MetaNamespaceKeyFunc(sc)
+	if err != nil {
+		panic(err)
+	}
+	desired := corev1ac.Secret(name, namespace).WithType(secretType(sc))
+	if sc.Spec.Immutable {
+		desired.WithImmutable(true)
+	}
+	if tmpl := sc.Spec.SecretTemplate; tmpl != nil {
+		desired.WithLabels(tmpl.Labels).WithAnnotations(tmpl.Annotations)
+	}
+	desired.WithLabels(map[string]string{v1beta1.LabelClaimUID: string(sc.UID), v1beta1.LabelReplicaOf: string(sc.UID)}).
+		WithAnnotations(map[string]string{v1beta1.AnnotationReplicaOf: key}).
+		WithData(data)
+	if existing != nil {
+		applied, err := corev1ac.ExtractSecret(existing, fieldManager)
+		if err != nil {
+			return fmt.Errorf("failed to extract applied fields of replica: %w", err)
+		}
+		if equality.Semantic.DeepEqual(applied, desired) {
+			return nil
+		}
+		if secretTypeOf(existing) != secretType(sc) {
+			if err := c.deleteForTypeChange(ctx, sc, existing); err != nil {
+				return err
+			}
+		}
+	}
+	replica, err := c.kclient.CoreV1().Secrets(namespace).Apply(ctx, desired, metav1.ApplyOptions{FieldManager: fieldManager})
+	if apierrors.IsConflict(err) {
+		return withReason(reasonFieldConflict, fmt.Errorf("fields of replica in namespace \"%s\" are managed by others: %w", namespace, err))
+	}
+	if err != nil {
+		return withReason(reasonReplicationFailed, fmt.Errorf("failed to replicate secret to namespace \"%s\": %w", namespace, err))
+	}
+	c.recorder.Eventf(sc, replica, corev1.EventTypeNormal, "SecretReplicated", "Replicate", "Replicated secret \"%s\" to namespace \"%s\"", name, namespace)
+	return nil
+}
+
+// removeReplicas deletes all replicas of the secret of a claim, or releases them if retain is set.
+func (c *controller) removeReplicas(ctx context.Context, sc *v1beta1.SecretClaim, retain bool) error {
+	replicas, err := c.listSecretMetadata(labels.SelectorFromSet(labels.Set{v1beta1.LabelReplicaOf: string(sc.UID)}))
+	if err != nil {
+		return err
+	}
+	for _, replica := range replicas {
+		if retain {
+			err = c.releaseSecret(ctx, sc, replica)
+		} else {
+			err = c.deleteSecret(ctx, sc, replica, "the claim is deleted")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons used in status conditions
+const (
+	reasonReconciled            = "Reconciled"
+	reasonReconcileFailed       = "ReconcileFailed"
+	reasonInvalidSpec           = "InvalidSpec"
+	reasonCAUnavailable         = "CAUnavailable"
+	reasonIssuanceFailed        = "IssuanceFailed"
+	reasonSecretCreateFailed    = "SecretCreateFailed"
+	reasonSecretPatchFailed     = "SecretPatchFailed"
+	reasonSecretDeleteFailed    = "SecretDeleteFailed"
+	reasonSecretConflict        = "SecretConflict"
+	reasonFieldConflict         = "FieldConflict"
+	reasonSourceUnavailable     = "SourceUnavailable"
+	reasonCertificateIssued     = "CertificateIssued"
+	reasonWithinValidity        = "WithinValidity"
+	reasonNearingExpiry         = "NearingExpiry"
+	reasonNoExpiry              = "NoExpiry"
+	reasonReplicated            = "Replicated"
+	reasonReplicationNotAllowed = "ReplicationNotAllowed"
+	reasonReplicaConflict       = "ReplicaConflict"
+	reasonReplicationFailed     = "ReplicationFailed"
+	reasonPolicyViolation       = "PolicyViolation"
+	reasonInsufficientEntropy   = "InsufficientEntropy"
+)
+
+// reasonError annotates an error with a machine-readable reason which is surfaced in the Ready condition.
+type reasonError struct {
+	reason string
+	err    error
+}
+
+func (e *reasonError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reasonError) Unwrap() error {
+	return e.err
+}
+
+func withReason(reason string, err error) error {
+	return &reasonError{reason: reason, err: err}
+}
+
+// errorReason returns the reason of the outermost reasonError in the error chain or reasonReconcileFailed if there
+// is none.
+func errorReason(err error) string {
+	var re *reasonError
+	if errors.As(err, &re) {
+		return re.reason
+	}
+	return reasonReconcileFailed
+}
+
+func setCondition(sc *v1beta1.SecretClaim, status *v1beta1.SecretClaimStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: sc.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// updateStatus writes status to the status subresource of the claim if it differs from the current one.
+func (c *controller) updateStatus(ctx context.Context, sc *v1beta1.SecretClaim, status *v1beta1.SecretClaimStatus) error {
+	if equality.Semantic.DeepEqual(&sc.Status, status) {
+		return nil
+	}
+	newSC := sc.DeepCopy()
+	newSC.Status = *status
+	if _, err := c.dsclient.DolansoftV1beta1().SecretClaims(sc.Namespace).UpdateStatus(ctx, newSC, metav1.UpdateOptions{FieldManager: fieldManager}); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	return nil
+}
+
+// clearCertificateStatus removes all certificate-related information from status, for claims which are not (or no
+// longer) X.509 claims.
+func clearCertificateStatus(status *v1beta1.SecretClaimStatus) {
+	status.Certificate = nil
+	status.LastRotationTime = nil
+	meta.RemoveStatusCondition(&status.Conditions, v1beta1.ConditionIssued)
+	meta.RemoveStatusCondition(&status.Conditions, v1beta1.ConditionRotationDue)
+}
+
+func parseCertificatePEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	if block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("unexpected PEM block type \"%s\"", block.Type)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func certificateStatus(cert *x509.Certificate) *v1beta1.CertificateStatus {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return &v1beta1.CertificateStatus{
+		NotAfter:     metav1.NewTime(cert.NotAfter),
+		SerialNumber: cert.SerialNumber.Text(16),
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}
+}
+
+// rotationTime returns the point in time after which a certificate is due for rotation, which is after two thirds of
+// its validity period have elapsed. Certificates which never expire are never rotated and return the zero time.
+func rotationTime(cert *x509.Certificate) time.Time {
+	if !cert.NotAfter.Before(unknownNotAfter) {
+		return time.Time{}
+	}
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotBefore.Add(validity * 2 / 3)
+}
+
+// certificateMatchesClaim checks if an existing certificate and its private key still match the claim. caCert is the
+// current certificate of the CA named by the claim or nil if the certificate is self-signed.
+func certificateMatchesClaim(claim *v1beta1.SecretClaim, cert *x509.Certificate, keyPEM []byte, caCert *x509.Certificate) bool {
+	x509spec := claim.Spec.X509Claim
+	if cert.Subject.CommonName != certificateCommonName(claim) {
+		return false
+	}
+	if cert.IsCA != x509spec.IsCA {
+		return false
+	}
+	expectedNames := certificateDNSNames(claim)
+	actualNames := append([]string(nil), cert.DNSNames...)
+	if len(expectedNames) != len(actualNames) {
+		return false
+	}
+	sort.Strings(expectedNames)
+	sort.Strings(actualNames)
+	for i := range expectedNames {
+		if expectedNames[i] != actualNames[i] {
+			return false
+		}
+	}
+	// Certificates signed by a CA which has been reissued since no longer chain to it
+	issuer := cert
+	if caCert != nil {
+		issuer = caCert
+	}
+	if !bytes.Equal(cert.RawIssuer, issuer.RawSubject) ||
+		issuer.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) != nil {
+		return false
+	}
+	if x509spec.RotateEvery == "" {
+		if cert.NotAfter.Before(unknownNotAfter) {
+			return false
+		}
+	} else {
+		lifetime, err := v1beta1.ParseRotateEvery(x509spec.RotateEvery)
+		// Both ends of the validity period are truncated to whole seconds
+		if validity := cert.NotAfter.Sub(cert.NotBefore); err != nil || validity < lifetime-time.Second || validity > lifetime+time.Second {
+			return false
+		}
+	}
+	key, keyType, err := parsePrivateKeyPEM(keyPEM)
+	if err != nil || (keyType == legacyKeyType) != x509spec.LegacySEC1PrivateKey {
+		return false
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return false
+	}
+	public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && public.Equal(cert.PublicKey)
+}
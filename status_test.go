@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRotationTime(t *testing.T) {
+	notBefore := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		want     time.Time
+	}{
+		{"Eternal certificate", unknownNotAfter, time.Time{}},
+		{"Rotates after two thirds", notBefore.Add(90 * time.Hour), notBefore.Add(60 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &x509.Certificate{NotBefore: notBefore, NotAfter: tt.notAfter}
+			if got := rotationTime(cert); !got.Equal(tt.want) {
+				t.Errorf("rotationTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"Plain error", errors.New("test"), reasonReconcileFailed},
+		{"Annotated error", withReason(reasonCAUnavailable, errors.New("test")), reasonCAUnavailable},
+		{"Wrapped annotated error", fmt.Errorf("outer: %w", withReason(reasonInvalidSpec, errors.New("test"))), reasonInvalidSpec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorReason(tt.err); got != tt.want {
+				t.Errorf("errorReason() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificateMatchesClaim(t *testing.T) {
+	ctx := context.Background()
+	newClaim := func(name string, x509Claim v1beta1.X509Claim) *v1beta1.SecretClaim {
+		return &v1beta1.SecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Spec:       v1beta1.SecretClaimSpec{X509Claim: &x509Claim},
+		}
+	}
+	tc := newTestController(t)
+	issue := func(claim *v1beta1.SecretClaim) (*x509.Certificate, map[string][]byte) {
+		data := make(map[string][]byte)
+		cert, err := tc.issueCertificate(ctx, claim, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tc.kclient.CoreV1().Secrets("default").Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: claim.Name, Namespace: "default"},
+			Data:       data,
+		}, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		tc.syncCaches(t)
+		return cert, data
+	}
+	caCert, _ := issue(newClaim("ca", v1beta1.X509Claim{IsCA: true}))
+	otherCACert, _ := issue(newClaim("other-ca", v1beta1.X509Claim{IsCA: true}))
+	claim := newClaim("hello", v1beta1.X509Claim{
+		CASecretName: "ca", RotateEvery: "30d", ServiceNames: []string{"hello"}, ExtraNames: []string{"hello.example.com"},
+	})
+	cert, data := issue(claim)
+	_, otherData := issue(newClaim("other", v1beta1.X509Claim{CASecretName: "ca", RotateEvery: "30d"}))
+	selfSignedClaim := newClaim("self-signed", v1beta1.X509Claim{LegacySEC1PrivateKey: true})
+	selfSignedCert, selfSignedData := issue(selfSignedClaim)
+
+	tests := []struct {
+		name   string
+		claim  *v1beta1.SecretClaim
+		cert   *x509.Certificate
+		keyPEM []byte
+		caCert *x509.Certificate
+		modify func(x *v1beta1.X509Claim)
+		want   bool
+	}{
+		{"Matching certificate", claim, cert, data["tls.key"], caCert, nil, true},
+		{"Different common name", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.CommonName = "other" }, false},
+		{"Missing DNS name", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.ExtraNames = nil }, false},
+		{"Different DNS name", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.ExtraNames = []string{"other.example.com"} }, false},
+		{"CA certificate", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.IsCA = true }, false},
+		{"Different CA", claim, cert, data["tls.key"], otherCACert, nil, false},
+		{"Self-signed instead of CA", selfSignedClaim, selfSignedCert, selfSignedData["tls.key"], caCert, nil, false},
+		{"CA removed", claim, cert, data["tls.key"], nil, func(x *v1beta1.X509Claim) { x.CASecretName = "" }, false},
+		{"Different lifetime", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.RotateEvery = "90d" }, false},
+		{"Lifetime removed", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.RotateEvery = "" }, false},
+		{"Missing key", claim, cert, nil, caCert, nil, false},
+		{"Key of another certificate", claim, cert, otherData["tls.key"], caCert, nil, false},
+		{"Legacy key format requested", claim, cert, data["tls.key"], caCert, func(x *v1beta1.X509Claim) { x.LegacySEC1PrivateKey = true }, false},
+		{"Matching self-signed certificate", selfSignedClaim, selfSignedCert, selfSignedData["tls.key"], nil, nil, true},
+		{"PKCS #8 key format requested", selfSignedClaim, selfSignedCert, selfSignedData["tls.key"], nil, func(x *v1beta1.X509Claim) { x.LegacySEC1PrivateKey = false }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := tt.claim.DeepCopy()
+			if tt.modify != nil {
+				tt.modify(claim.Spec.X509Claim)
+			}
+			if got := certificateMatchesClaim(claim, tt.cert, tt.keyPEM, tt.caCert); got != tt.want {
+				t.Errorf("certificateMatchesClaim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	dolansoftv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1"
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	clientset "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned"
+	admissionv1 "k8s.io/api/admission/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxAdmissionReviewSize limits the size of admission and conversion requests.
+const maxAdmissionReviewSize = 3 * 1024 * 1024
+
+// readReview reads the body of a review request from the API server. If it fails, it responds with an error and
+// returns false.
+func readReview(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAdmissionReviewSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+func writeReview(w http.ResponseWriter, review interface{}) {
+	out, err := json.Marshal(review)
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(out); err != nil {
+		log.Printf("Failed to write review response: %v", err)
+	}
+}
+
+// validatingWebhook implements a validating admission webhook for SecretClaims. It rejects claims with specs the
+// controller cannot reconcile or which violate a SecretClaimPolicy using the same checks as the controller itself.
+// It is served by all replicas, which don't have the caches of the leader, so policies and namespaces are read from
+// the API server.
+type validatingWebhook struct {
+	kclient             kubernetes.Interface
+	dsclient            clientset.Interface
+	minTokenEntropyBits int32
+}
+
+func (v *validatingWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, ok := readReview(w, r)
+	if !ok {
+		return
+	}
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request is not an AdmissionReview", http.StatusBadRequest)
+		return
+	}
+	review.Response = v.admitSecretClaim(r.Context(), review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+	writeReview(w, &review)
+}
+
+// serveConvertSecretClaim implements the conversion webhook converting SecretClaims between v1beta1 and v1.
+func serveConvertSecretClaim(w http.ResponseWriter, r *http.Request) {
+	body, ok := readReview(w, r)
+	if !ok {
+		return
+	}
+	var review apiextensionsv1.ConversionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request is not a ConversionReview", http.StatusBadRequest)
+		return
+	}
+	review.Response = &apiextensionsv1.ConversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range review.Request.Objects {
+		converted, err := convertSecretClaim(obj.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			review.Response.ConvertedObjects = nil
+			review.Response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		review.Response.ConvertedObjects = append(review.Response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	review.Request = nil
+	writeReview(w, &review)
+}
+
+// convertSecretClaim converts a serialized claim to the desired API version.
+func convertSecretClaim(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to decode object: %w", err)
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+	var converted interface{}
+	switch {
+	case typeMeta.APIVersion == v1beta1.SchemeGroupVersion.String() && desiredAPIVersion == dolansoftv1.SchemeGroupVersion.String():
+		var sc v1beta1.SecretClaim
+		if err := json.Unmarshal(raw, &sc); err != nil {
+			return nil, fmt.Errorf("failed to decode SecretClaim: %w", err)
+		}
+		out, err := dolansoftv1.ConvertFromV1beta1(&sc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert SecretClaim \"%s/%s\": %w", sc.Namespace, sc.Name, err)
+		}
+		converted = out
+	case typeMeta.APIVersion == dolansoftv1.SchemeGroupVersion.String() && desiredAPIVersion == v1beta1.SchemeGroupVersion.String():
+		var sc dolansoftv1.SecretClaim
+		if err := json.Unmarshal(raw, &sc); err != nil {
+			return nil, fmt.Errorf("failed to decode SecretClaim: %w", err)
+		}
+		out, err := sc.ConvertToV1beta1()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert SecretClaim \"%s/%s\": %w", sc.Namespace, sc.Name, err)
+		}
+		converted = out
+	default:
+		return nil, fmt.Errorf("unsupported conversion from %s to %s", typeMeta.APIVersion, desiredAPIVersion)
+	}
+	return json.Marshal(converted)
+}
+
+// admitSecretClaimDeletion rejects foreground cascading deletion of claims with DeletionPolicyRetain. The garbage
+// collector would delete their secret before the controller can release it.
+func admitSecretClaimDeletion(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	var sc v1beta1.SecretClaim
+	var options metav1.DeleteOptions
+	if json.Unmarshal(req.OldObject.Raw, &sc) != nil || json.Unmarshal(req.Options.Raw, &options) != nil {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	if sc.Spec.DeletionPolicy != v1beta1.DeletionPolicyRetain || options.PropagationPolicy == nil ||
+		*options.PropagationPolicy != metav1.DeletePropagationForeground {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	return &admissionv1.AdmissionResponse{Result: &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusForbidden,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: "foreground cascading deletion would delete the secret of a claim with deletionPolicy Retain, use background deletion instead",
+	}}
+}
+
+func (v *validatingWebhook) admitSecretClaim(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation == admissionv1.Delete {
+		return admitSecretClaimDeletion(req)
+	}
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	}
+	var sc v1beta1.SecretClaim
+	if err := json.Unmarshal(req.Object.Raw, &sc); err != nil {
+		return &admissionv1.AdmissionResponse{Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Reason:  metav1.StatusReasonBadRequest,
+			Message: fmt.Sprintf("failed to decode SecretClaim: %v", err),
+		}}
+	}
+	if req.Operation == admissionv1.Update {
+		// Claims which were created before validation existed must not get stuck, for example when the controller
+		// updates their finalizers. Only reject changes to the spec.
+		var oldSC v1beta1.SecretClaim
+		if err := json.Unmarshal(req.OldObject.Raw, &oldSC); err == nil && equality.Semantic.DeepEqual(oldSC.Spec, sc.Spec) {
+			return &admissionv1.AdmissionResponse{Allowed: true}
+		}
+	}
+	if errs := sc.Validate(); len(errs) > 0 {
+		return &admissionv1.AdmissionResponse{Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusUnprocessableEntity,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: errs.ToAggregate().Error(),
+		}}
+	}
+	sizes := v.wordListSizes(ctx, &sc, req.Namespace)
+	if errs := sc.Spec.ValidateMinEntropy(v.minTokenEntropyBits, sizes); len(errs) > 0 {
+		return &admissionv1.AdmissionResponse{Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusUnprocessableEntity,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: errs.ToAggregate().Error(),
+		}}
+	}
+	errs, err := v.policyViolations(ctx, &sc, req.Namespace, sizes)
+	if err != nil {
+		return &admissionv1.AdmissionResponse{Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusInternalServerError,
+			Reason:  metav1.StatusReasonInternalError,
+			Message: fmt.Sprintf("failed to evaluate policies: %v", err),
+		}}
+	}
+	if len(errs) > 0 {
+		return &admissionv1.AdmissionResponse{Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: errs.ToAggregate().Error(),
+		}}
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// wordListSizes reads the word lists of passphrases from the API server. Lists which cannot be read are left out, the
+// entropy of their passphrases is checked by the controller once they exist.
+func (v *validatingWebhook) wordListSizes(ctx context.Context, sc *v1beta1.SecretClaim, namespace string) v1beta1.WordListSizes {
+	sizes := make(v1beta1.WordListSizes)
+	for field, tokenSpec := range sc.Spec.CustomTokenFields {
+		if tokenSpec.Encoding != "passphrase" || tokenSpec.Passphrase == nil || tokenSpec.Passphrase.WordListRef == nil {
+			continue
+		}
+		ref := tokenSpec.Passphrase.WordListRef
+		configMap, err := v.kclient.CoreV1().ConfigMaps(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		if text, ok := configMap.Data[ref.Key]; ok {
+			sizes[field] = wordListSize(parseWordList(text))
+		}
+	}
+	return sizes
+}
+
+// policyViolations evaluates the policies applying to a claim in the given namespace.
+func (v *validatingWebhook) policyViolations(ctx context.Context, sc *v1beta1.SecretClaim, namespace string, wordListSizes v1beta1.WordListSizes) (field.ErrorList, error) {
+	policies, err := v.dsclient.DolansoftV1beta1().SecretClaimPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+	ns, err := v.kclient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ns = nil
+	} else if err != nil {
+		return nil, err
+	}
+	var policyPtrs []*v1beta1.SecretClaimPolicy
+	for i := range policies.Items {
+		policyPtrs = append(policyPtrs, &policies.Items[i])
+	}
+	// Policies are evaluated against the defaulted claim just like in the controller
+	desired := sc.DeepCopy()
+	desired.Default()
+	return policyViolations(desired, policyPtrs, ns, wordListSizes)
+}
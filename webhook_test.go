@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dolansoftv1 "git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1"
+	"git.dolansoft.org/dolansoft/k8s-generic-secrets/apis/dolansoft.org/v1beta1"
+	dsfake "git.dolansoft.org/dolansoft/k8s-generic-secrets/generated/clientset/versioned/fake"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidatingWebhook(t *testing.T) {
+	valid := v1beta1.SecretClaimSpec{
+		TokenFields: []string{"token"},
+		CustomTokenFields: map[string]v1beta1.CustomTokenSpec{
+			"password": {Length: 16, Encoding: "characterset", CharacterSet: "abc"},
+		},
+	}
+	passphrase := func(configMap string) v1beta1.CustomTokenSpec {
+		return v1beta1.CustomTokenSpec{Length: 8, Encoding: "passphrase", Passphrase: &v1beta1.PassphraseSpec{
+			WordListRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: configMap}, Key: "list"},
+		}}
+	}
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		oldSpec     *v1beta1.SecretClaimSpec
+		spec        v1beta1.SecretClaimSpec
+		wantAllowed bool
+		wantMessage string
+	}{
+		{"Valid claim", admissionv1.Create, nil, valid, true, ""},
+		{"Unknown encoding", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"password": {Length: 16, Encoding: "base65"}},
+		}, false, "spec.customTokenFields[password].encoding: Unsupported value"},
+		{"Empty character set", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"password": {Length: 16, Encoding: "characterset"}},
+		}, false, "spec.customTokenFields[password].characterSet: Required value"},
+		{"Too long", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"password": {Length: 2 * 1024 * 1024, Encoding: "hex"}},
+		}, false, "refusing to issue tokens larger than 1 MiB"},
+		{"Unparseable rotateEvery", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			X509Claim: &v1beta1.X509Claim{RotateEvery: "soon"},
+		}, false, "spec.x509.rotateEvery: Invalid value"},
+		{"X.509 with ignored token fields", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token"},
+			X509Claim:   &v1beta1.X509Claim{},
+		}, false, "spec.tokenFields: Forbidden: is ignored for X.509 claims"},
+		{"Duplicate key", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			TokenFields: []string{"token"},
+			FixedFields: map[string]string{"token": "fixed"},
+		}, false, "spec.fixedFields[token]: Duplicate value"},
+		{"Invalid update", admissionv1.Update, &valid, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"password": {Encoding: "hex"}},
+		}, false, "length needs to be at least 1"},
+		{"Metadata update of existing invalid claim", admissionv1.Update, &v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"password": {Encoding: "hex"}},
+		}, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"password": {Encoding: "hex"}},
+		}, true, ""},
+		{"Policy violation", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			FixedFields: map[string]string{"user": "admin"},
+		}, false, "spec.fixedFields: Forbidden: fixed fields are not allowed by SecretClaimPolicy no-fixed-fields"},
+		{"Insufficient entropy", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"pin": {Length: 4, Encoding: "characterset", CharacterSet: "0123456789"}},
+		}, false, "spec.customTokenFields[pin]: Forbidden: tokens have 13.3 bits of entropy, at least 20 are required"},
+		{"Low entropy allowed", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"pin": {Length: 4, Encoding: "characterset", CharacterSet: "0123456789", AllowLowEntropy: true}},
+		}, true, ""},
+		{"Passphrase from a small word list", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"psk": passphrase("words")},
+		}, false, "spec.customTokenFields[psk]: Forbidden: tokens have 16.0 bits of entropy, at least 20 are required"},
+		{"Passphrase from an unreadable word list", admissionv1.Create, nil, v1beta1.SecretClaimSpec{
+			CustomTokenFields: map[string]v1beta1.CustomTokenSpec{"psk": passphrase("missing")},
+		}, true, ""},
+		{"Delete", admissionv1.Delete, nil, v1beta1.SecretClaimSpec{}, true, ""},
+	}
+	allowFixedFields := false
+	webhook := &validatingWebhook{
+		kclient: kubefake.NewSimpleClientset(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "words", Namespace: "default"},
+			Data:       map[string]string{"list": "alpha\nbravo\ncharlie\ndelta\n"},
+		}),
+		dsclient: dsfake.NewSimpleClientset(&v1beta1.SecretClaimPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-fixed-fields"},
+			Spec:       v1beta1.SecretClaimPolicySpec{AllowFixedFields: &allowFixedFields},
+		}),
+		minTokenEntropyBits: 20,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encode := func(spec *v1beta1.SecretClaimSpec) runtime.RawExtension {
+				raw, err := json.Marshal(&v1beta1.SecretClaim{Spec: *spec})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return runtime.RawExtension{Raw: raw}
+			}
+			review := admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+				UID:       "request-uid",
+				Namespace: "default",
+				Operation: tt.operation,
+				Object:    encode(&tt.spec),
+			}}
+			if tt.oldSpec != nil {
+				review.Request.OldObject = encode(tt.oldSpec)
+			}
+			body, err := json.Marshal(&review)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			webhook.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate-secretclaim", bytes.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+			}
+			var response admissionv1.AdmissionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Response.UID != "request-uid" {
+				t.Errorf("response UID = %q, want request-uid", response.Response.UID)
+			}
+			if response.Response.Allowed != tt.wantAllowed {
+				t.Fatalf("allowed = %v, want %v (%v)", response.Response.Allowed, tt.wantAllowed, response.Response.Result)
+			}
+			if !tt.wantAllowed && !strings.Contains(response.Response.Result.Message, tt.wantMessage) {
+				t.Errorf("message %q does not contain %q", response.Response.Result.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestValidatingWebhookDeletion(t *testing.T) {
+	foreground, background := metav1.DeletePropagationForeground, metav1.DeletePropagationBackground
+	tests := []struct {
+		name        string
+		policy      v1beta1.DeletionPolicy
+		propagation *metav1.DeletionPropagation
+		wantAllowed bool
+	}{
+		{"Retain with default propagation", v1beta1.DeletionPolicyRetain, nil, true},
+		{"Retain with background propagation", v1beta1.DeletionPolicyRetain, &background, true},
+		{"Retain with foreground propagation", v1beta1.DeletionPolicyRetain, &foreground, false},
+		{"Delete with foreground propagation", v1beta1.DeletionPolicyDelete, &foreground, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldObject, err := json.Marshal(&v1beta1.SecretClaim{Spec: v1beta1.SecretClaimSpec{DeletionPolicy: tt.policy}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			options, err := json.Marshal(&metav1.DeleteOptions{PropagationPolicy: tt.propagation})
+			if err != nil {
+				t.Fatal(err)
+			}
+			response := admitSecretClaimDeletion(&admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				OldObject: runtime.RawExtension{Raw: oldObject},
+				Options:   runtime.RawExtension{Raw: options},
+			})
+			if response.Allowed != tt.wantAllowed {
+				t.Errorf("allowed = %v, want %v (%v)", response.Allowed, tt.wantAllowed, response.Result)
+			}
+		})
+	}
+}
+
+func TestConversionWebhook(t *testing.T) {
+	beta := `{"apiVersion":"dolansoft.org/v1beta1","kind":"SecretClaim","metadata":{"name":"test"},"spec":{"tokenFields":["token"],"fixedFields":{"user":"admin"}}}`
+	tests := []struct {
+		name        string
+		objects     []string
+		desired     string
+		wantSuccess bool
+		wantFields  [][]dolansoftv1.SecretField
+	}{
+		{"Upgrade", []string{beta}, "dolansoft.org/v1", true, [][]dolansoftv1.SecretField{{
+			{Key: "token", Token: &dolansoftv1.TokenSpec{Length: 16, Encoding: dolansoftv1.TokenEncodingHex}},
+			{Key: "user", Value: &[]string{"admin"}[0]},
+		}}},
+		{"Already converted", []string{`{"apiVersion":"dolansoft.org/v1","kind":"SecretClaim","spec":{}}`}, "dolansoft.org/v1", true, [][]dolansoftv1.SecretField{nil}},
+		{"Unsupported version", []string{beta}, "dolansoft.org/v2", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := apiextensionsv1.ConversionReview{Request: &apiextensionsv1.ConversionRequest{
+				UID:               "request-uid",
+				DesiredAPIVersion: tt.desired,
+			}}
+			for _, obj := range tt.objects {
+				review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: []byte(obj)})
+			}
+			body, err := json.Marshal(&review)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			serveConvertSecretClaim(rec, httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(body)))
+			var response apiextensionsv1.ConversionReview
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Response.UID != "request-uid" {
+				t.Errorf("response UID = %q, want request-uid", response.Response.UID)
+			}
+			if success := response.Response.Result.Status == metav1.StatusSuccess; success != tt.wantSuccess {
+				t.Fatalf("success = %v, want %v (%v)", success, tt.wantSuccess, response.Response.Result.Message)
+			}
+			if len(response.Response.ConvertedObjects) != len(tt.wantFields) {
+				t.Fatalf("got %d converted objects, want %d", len(response.Response.ConvertedObjects), len(tt.wantFields))
+			}
+			for i, obj := range response.Response.ConvertedObjects {
+				var sc dolansoftv1.SecretClaim
+				if err := json.Unmarshal(obj.Raw, &sc); err != nil {
+					t.Fatal(err)
+				}
+				if sc.APIVersion != tt.desired {
+					t.Errorf("apiVersion = %q, want %q", sc.APIVersion, tt.desired)
+				}
+				if !equality.Semantic.DeepEqual(sc.Spec.Fields, tt.wantFields[i]) {
+					t.Errorf("fields = %+v, want %+v", sc.Spec.Fields, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}